@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// Exit codes are part of the command-line interface; scripts and CI
+// pipelines rely on them to tell failure classes apart.
+const (
+	exitOK         = 0
+	exitUsage      = 2
+	exitInput      = 3
+	exitValidation = 4
+	exitOutput     = 5
+)
+
+func fail(code int, format string, args ...any) int {
+	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
+	return code
+}
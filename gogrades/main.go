@@ -1,65 +1,589 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/andreaswillibaldweber/gogrades/internal/cli"
 	"github.com/andreaswillibaldweber/gogrades/internal/grades"
 	"github.com/andreaswillibaldweber/gogrades/internal/gui"
+	"github.com/andreaswillibaldweber/gogrades/internal/project"
 	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
 )
 
+type options interface {
+	Command() string
+	Args() []string
+	IsSet(name string) bool
+	ProjectFile() string
+	GKey() bool
+	PMax() float64
+	PPass() float64
+	CSVFile() string
+	SaveCSV() bool
+	Format() string
+	OutFile() string
+	ExamNr() string
+	Semester() string
+	OutDir() string
+	Template() string
+	Force() bool
+	Quiet() bool
+	MatRegex() string
+	CorrectionsFile() string
+	MatNr() string
+	Points() float64
+	Reason() string
+	Examiner() string
+	SecondFile() string
+	Rule() string
+	Threshold() float64
+	RetakeFile() string
+	Policy() string
+	Step() string
+	Rounding() string
+	Granularity() float64
+	AllowedGrades() string
+	GradeMapping() string
+	Highlights() string
+	TopGrade() float64
+	Signature() bool
+}
+
 func main() {
-	flags := cli.ParseFlags()
-	fmt.Printf("Flags>> %s \n\n", flags)
+	os.Exit(run())
+}
+
+func run() int {
+	flags, err := cli.ParseFlags()
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		return fail(exitUsage, "%v", err)
+	}
+	if !flags.Quiet() {
+		fmt.Fprintf(os.Stderr, "Flags>> %s \n\n", flags)
+	}
 
-	exam := grades.NewExam(flags.PMax(), flags.PPass())
+	switch flags.Command() {
+	case cli.CommandDiff:
+		return runDiff(flags)
+	case cli.CommandLog:
+		return runCorrectionsLog(flags)
+	case cli.CommandCourse:
+		return runCourse(flags)
+	}
 
-	if flags.GKey() {
-		fmt.Println(exam.GradingKeyString())
+	proj, err := loadProject(flags)
+	if errors.Is(err, project.ErrInvalid) {
+		return fail(exitValidation, "%v", err)
+	}
+	if err != nil {
+		return fail(exitInput, "loading project: %v", err)
+	}
+
+	switch flags.Command() {
+	case cli.CommandKey:
+		return runKey(proj)
+	case cli.CommandGrade:
+		return runGrade(flags, proj)
+	case cli.CommandStats:
+		return runStats(proj)
+	case cli.CommandExport:
+		return runExport(flags, proj)
+	case cli.CommandValidate:
+		return runValidate(flags, proj)
+	case cli.CommandCorrect:
+		return runCorrect(flags, proj)
+	case cli.CommandReconcile:
+		return runReconcile(flags, proj)
+	case cli.CommandRetake:
+		return runRetake(flags, proj)
+	case cli.CommandGUI:
+		return runGUI(flags, proj)
 	}
+	return fail(exitUsage, "unknown command %q", flags.Command())
+}
 
-	if flags.GUI() {
-		err := gui.ShowExamTables(flags.PMax(), flags.PPass(), flags.CSVFile())
+func loadProject(flags options) (project.Project, error) {
+	proj := project.New(flags.PMax(), flags.PPass(), flags.CSVFile())
+	if strings.TrimSpace(flags.ProjectFile()) != "" {
+		loaded, err := project.Load(flags.ProjectFile())
+		if err != nil {
+			return proj, err
+		}
+		proj = loaded
+		pMax, pPass := proj.PMax(), proj.PPass()
+		if flags.IsSet("pmax") {
+			pMax = flags.PMax()
+		}
+		if flags.IsSet("ppass") {
+			pPass = flags.PPass()
+		}
+		proj.SetPoints(pMax, pPass)
+		if strings.TrimSpace(flags.CSVFile()) != "" {
+			proj.SetStudentFile(flags.CSVFile())
+		}
+	}
+	step, rounding, granularity := proj.Step(), proj.Rounding(), proj.Granularity()
+	if flags.IsSet("step") {
+		step = flags.Step()
+	}
+	if flags.IsSet("rounding") {
+		rounding = flags.Rounding()
+	}
+	if flags.IsSet("granularity") {
+		granularity = flags.Granularity()
+	}
+	proj.SetRounding(step, rounding, granularity)
+	allowed, mapping := proj.AllowedGrades(), proj.GradeMapping()
+	if flags.IsSet("allowed") {
+		list, err := grades.ParseGradeList(flags.AllowedGrades())
 		if err != nil {
-			fmt.Printf("Error showing GUI: %v\n", err)
-			return
+			return proj, fmt.Errorf("%w: --allowed: %v", project.ErrInvalid, err)
 		}
-		return
+		allowed = list
+	}
+	if flags.IsSet("mapping") {
+		mapping = flags.GradeMapping()
+	}
+	proj.SetGradeSet(allowed, mapping)
+	highlights, topGrade := proj.Highlights(), proj.TopGrade()
+	if flags.IsSet("highlight") {
+		highlights = grades.ParseHighlights(flags.Highlights())
+	}
+	if flags.IsSet("topgrade") {
+		topGrade = flags.TopGrade()
+	}
+	proj.SetHighlights(highlights, topGrade)
+	if strings.TrimSpace(flags.CorrectionsFile()) != "" {
+		proj.SetCorrectionsFile(flags.CorrectionsFile())
+	}
+	if strings.TrimSpace(flags.OutDir()) != "" {
+		proj.SetOutDir(flags.OutDir())
 	}
-	if strings.TrimSpace(flags.CSVFile()) == "" {
-		fmt.Println("Error: --csvfile is required when not using --gui.")
-		return
+	if strings.TrimSpace(flags.Template()) != "" {
+		proj.SetTemplate(flags.Template())
 	}
+	return proj, proj.Validate()
+}
 
-	table, err := utilities.NewTableFromCSV(flags.CSVFile())
-	if err != nil {
-		fmt.Printf("Error reading CSV: %v\n", err)
-		return
+func runKey(proj project.Project) int {
+	exam := grades.NewExam(proj.PMax(), proj.PPass())
+	if err := exam.Configure(proj); err != nil {
+		return fail(exitValidation, "%v", err)
 	}
-	students, err := grades.NewStudentsFromTable(table)
+	fmt.Println(exam.GradingKeyString())
+	return exitOK
+}
+
+func runGrade(flags options, proj project.Project) int {
+	exam, err := grades.NewExamFromConfig(proj)
 	if err != nil {
-		fmt.Printf("Error parsing students from table: %v\n", err)
-		return
+		return fail(exitInput, "loading exam: %v", err)
 	}
-	exam.AddStudents(students)
 
-	if flags.GStud() {
-		fmt.Println(exam.GradedStudentString())
+	if flags.GKey() {
+		fmt.Println(exam.GradingKeyString())
 	}
+	fmt.Println(exam.GradedStudentString())
 
 	if flags.SaveCSV() {
-		newpathGradingKey := strings.TrimSuffix(flags.CSVFile(), filepath.Ext(flags.CSVFile())) + "-grading-key.csv"
-		newpathGradedStudent := strings.TrimSuffix(flags.CSVFile(), filepath.Ext(flags.CSVFile())) + "-graded.csv"
+		newpathGradingKey := proj.OutputPath(project.OutputGradingKey)
+		newpathGradedStudent := proj.OutputPath(project.OutputGraded)
+		if err := prepareOutputs([]string{newpathGradingKey, newpathGradedStudent}, flags.Force()); err != nil {
+			return fail(exitOutput, "writing CSV: %v", err)
+		}
 
 		err1 := exam.GradingKeyTable().ToCSV(newpathGradingKey)
 		err2 := exam.GradedStudentTable().ToCSV(newpathGradedStudent)
 		if err1 != nil || err2 != nil {
-			fmt.Printf("Error writing CSV: %v\n", err)
-			return
+			return fail(exitOutput, "writing CSV: %v", errors.Join(err1, err2))
+		}
+		if !flags.Quiet() {
+			fmt.Printf("Exam data saved as %s and %s.\n", newpathGradingKey, newpathGradedStudent)
+		}
+	}
+	return exitOK
+}
+
+func runStats(proj project.Project) int {
+	exam, err := grades.NewExamFromConfig(proj)
+	if err != nil {
+		return fail(exitInput, "loading exam: %v", err)
+	}
+	fmt.Println(exam.StatisticsString())
+	return exitOK
+}
+
+func runExport(flags options, proj project.Project) int {
+	switch flags.Format() {
+	case cli.ExportFormatHIS, cli.ExportFormatHISXLSX:
+		return exportHISinOne(flags, proj)
+	case cli.ExportFormatHTML:
+		return exportHTML(flags, proj)
+	case cli.ExportFormatPDF:
+		return exportPDF(flags, proj)
+	}
+	return fail(exitUsage, "unknown export format %q", flags.Format())
+}
+
+func exportHISinOne(flags options, proj project.Project) int {
+	if strings.TrimSpace(flags.ExamNr()) == "" || strings.TrimSpace(flags.Semester()) == "" {
+		return fail(exitUsage, "--examnr and --semester are required for the HISinOne/FlexNow export")
+	}
+	exam, err := grades.NewExamFromConfig(proj)
+	if err != nil {
+		return fail(exitInput, "loading exam: %v", err)
+	}
+
+	xlsx := flags.Format() == cli.ExportFormatHISXLSX
+	outFile := flags.OutFile()
+	if strings.TrimSpace(outFile) == "" {
+		outFile = proj.OutputPath(project.OutputHISinOne)
+		if xlsx {
+			outFile = proj.OutputPathExt(project.OutputHISinOne, ".xlsx")
+		}
+	}
+	if err := prepareOutputs([]string{outFile}, flags.Force()); err != nil {
+		return fail(exitOutput, "writing HISinOne export: %v", err)
+	}
+	export := grades.NewHISinOneExport(flags.ExamNr(), flags.Semester())
+	if xlsx {
+		err = export.ToXLSX(exam, outFile)
+	} else {
+		err = export.ToCSV(exam, outFile)
+	}
+	if err != nil {
+		return fail(exitOutput, "writing HISinOne export: %v", err)
+	}
+	if !flags.Quiet() {
+		fmt.Printf("HISinOne/FlexNow export saved as %s.\n", outFile)
+	}
+	return exitOK
+}
+
+func exportHTML(flags options, proj project.Project) int {
+	highlighter, err := grades.NewHighlighter(proj.Highlights(), proj.TopGrade())
+	if err != nil {
+		return fail(exitUsage, "%v", err)
+	}
+	exam, err := grades.NewExamFromConfig(proj)
+	if err != nil {
+		return fail(exitInput, "loading exam: %v", err)
+	}
+
+	outFile := flags.OutFile()
+	if strings.TrimSpace(outFile) == "" {
+		outFile = proj.OutputPathExt(project.OutputReport, ".html")
+	}
+	if err := prepareOutputs([]string{outFile}, flags.Force()); err != nil {
+		return fail(exitOutput, "writing HTML report: %v", err)
+	}
+	if err := exam.ToHTML(outFile, proj.ReportHeading(), highlighter); err != nil {
+		return fail(exitOutput, "writing HTML report: %v", err)
+	}
+	if !flags.Quiet() {
+		fmt.Printf("HTML report saved as %s.\n", outFile)
+	}
+	return exitOK
+}
+
+func exportPDF(flags options, proj project.Project) int {
+	exam, err := grades.NewExamFromConfig(proj)
+	if err != nil {
+		return fail(exitInput, "loading exam: %v", err)
+	}
+
+	outFile := flags.OutFile()
+	if strings.TrimSpace(outFile) == "" {
+		outFile = proj.OutputPathExt(project.OutputPrint, ".pdf")
+	}
+	if err := prepareOutputs([]string{outFile}, flags.Force()); err != nil {
+		return fail(exitOutput, "writing PDF: %v", err)
+	}
+	if err := exam.ToPDF(outFile, proj.PrintPage(flags.Signature())); err != nil {
+		return fail(exitOutput, "writing PDF: %v", err)
+	}
+	if !flags.Quiet() {
+		fmt.Printf("PDF saved as %s.\n", outFile)
+	}
+	return exitOK
+}
+
+func runValidate(flags options, proj project.Project) int {
+	validator, err := grades.NewValidator(proj.PMax(), flags.MatRegex())
+	if err != nil {
+		return fail(exitUsage, "%v", err)
+	}
+	issues, err := validator.ValidateCSV(proj.StudentFile())
+	if err != nil {
+		return fail(exitInput, "%s: %v", proj.StudentFile(), err)
+	}
+	if len(issues) > 0 {
+		fmt.Println(issues)
+		return fail(exitValidation, "%s is invalid: %d problem(s)", proj.StudentFile(), len(issues))
+	}
+	if !flags.Quiet() {
+		fmt.Printf("%s is valid.\n", proj.StudentFile())
+	}
+	return exitOK
+}
+
+func runDiff(flags options) int {
+	if !validOutputFormat(flags.Format()) {
+		return fail(exitUsage, "unknown output format %q", flags.Format())
+	}
+	diff, err := grades.DiffGradedCSV(flags.Args()[0], flags.Args()[1])
+	if err != nil {
+		return fail(exitInput, "%v", err)
+	}
+	if err := writeFormatted(os.Stdout, flags.Format(), diff, diff.Table()); err != nil {
+		return fail(exitOutput, "writing diff: %v", err)
+	}
+	return exitOK
+}
+
+func runCorrect(flags options, proj project.Project) int {
+	if strings.TrimSpace(proj.CorrectionsFile()) == "" {
+		return fail(exitUsage, "--corrections is required for %q", flags.Command())
+	}
+	if strings.TrimSpace(flags.MatNr()) == "" || !flags.IsSet("points") {
+		return fail(exitUsage, "--mat and --points are required for %q", flags.Command())
+	}
+	if strings.TrimSpace(flags.Reason()) == "" || strings.TrimSpace(flags.Examiner()) == "" {
+		return fail(exitUsage, "--reason and --examiner are required for %q", flags.Command())
+	}
+
+	exam, err := grades.NewExamFromConfig(proj)
+	if err != nil {
+		return fail(exitInput, "loading exam: %v", err)
+	}
+	correction, err := exam.Correct(flags.MatNr(), flags.Points(), flags.Reason(), flags.Examiner(), time.Now().UTC())
+	if err != nil {
+		return fail(exitValidation, "%v", err)
+	}
+	if err := grades.AppendCorrectionCSV(proj.CorrectionsFile(), correction); err != nil {
+		return fail(exitOutput, "%v", err)
+	}
+	if !flags.Quiet() {
+		fmt.Printf("Logged %s in %s.\n", correction, proj.CorrectionsFile())
+	}
+	return exitOK
+}
+
+func runCorrectionsLog(flags options) int {
+	if !validOutputFormat(flags.Format()) {
+		return fail(exitUsage, "unknown output format %q", flags.Format())
+	}
+	log, err := grades.NewCorrectionsFromCSV(flags.Args()[0])
+	if err != nil {
+		return fail(exitInput, "%v", err)
+	}
+
+	if strings.TrimSpace(flags.OutFile()) == "" {
+		err = writeFormatted(os.Stdout, flags.Format(), log, log.Table())
+	} else if err = prepareOutputs([]string{flags.OutFile()}, flags.Force()); err == nil {
+		err = utilities.WriteFileAtomic(flags.OutFile(), func(w io.Writer) error {
+			return writeFormatted(w, flags.Format(), log, log.Table())
+		})
+	}
+	if err != nil {
+		return fail(exitOutput, "writing corrections log: %v", err)
+	}
+	if strings.TrimSpace(flags.OutFile()) != "" && !flags.Quiet() {
+		fmt.Printf("Corrections log saved as %s.\n", flags.OutFile())
+	}
+	return exitOK
+}
+
+func runReconcile(flags options, proj project.Project) int {
+	if strings.TrimSpace(flags.SecondFile()) == "" {
+		return fail(exitUsage, "--second is required for %q", flags.Command())
+	}
+	reconciler, err := grades.NewReconciler(flags.Rule(), flags.Threshold())
+	if err != nil {
+		return fail(exitUsage, "%v", err)
+	}
+
+	first, err := grades.NewExamFromConfig(proj)
+	if err != nil {
+		return fail(exitInput, "loading first examiner's points: %v", err)
+	}
+	second, err := grades.NewExamFromCSV(proj.PMax(), proj.PPass(), flags.SecondFile())
+	if err != nil {
+		return fail(exitInput, "loading second examiner's points: %v", err)
+	}
+	merged, reconciliations, err := reconciler.Reconcile(first, second)
+	if err != nil {
+		return fail(exitValidation, "%v", err)
+	}
+
+	fmt.Println(reconciliations)
+	fmt.Println(merged.GradedStudentString())
+
+	if flags.SaveCSV() {
+		newpathGradedStudent := proj.OutputPath(project.OutputGraded)
+		newpathReconciliation := proj.OutputPath(project.OutputReconcile)
+		if err := prepareOutputs([]string{newpathGradedStudent, newpathReconciliation}, flags.Force()); err != nil {
+			return fail(exitOutput, "writing CSV: %v", err)
+		}
+		err1 := merged.GradedStudentTable().ToCSV(newpathGradedStudent)
+		err2 := reconciliations.Table().ToCSV(newpathReconciliation)
+		if err1 != nil || err2 != nil {
+			return fail(exitOutput, "writing CSV: %v", errors.Join(err1, err2))
+		}
+		if !flags.Quiet() {
+			fmt.Printf("Reconciliation saved as %s and %s.\n", newpathGradedStudent, newpathReconciliation)
+		}
+	}
+	if flagged := reconciliations.Flagged(); len(flagged) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d students differ by more than %.1f grades and need a third examiner.\n", len(flagged), flags.Threshold())
+	}
+	return exitOK
+}
+
+func runRetake(flags options, proj project.Project) int {
+	if strings.TrimSpace(flags.RetakeFile()) == "" {
+		return fail(exitUsage, "--retake is required for %q", flags.Command())
+	}
+	merger, err := grades.NewRetakeMerger(flags.Policy())
+	if err != nil {
+		return fail(exitUsage, "%v", err)
+	}
+
+	retakeProj := project.New(proj.PMax(), proj.PPass(), flags.RetakeFile())
+	retakeProj.SetRounding(proj.Step(), proj.Rounding(), proj.Granularity())
+	retakeProj.SetGradeSet(proj.AllowedGrades(), proj.GradeMapping())
+	if project.IsProjectFile(flags.RetakeFile()) {
+		retakeProj, err = project.Load(flags.RetakeFile())
+		if errors.Is(err, project.ErrInvalid) {
+			return fail(exitValidation, "%v", err)
+		}
+		if err != nil {
+			return fail(exitInput, "loading retake project: %v", err)
+		}
+	}
+
+	first, err := grades.NewExamFromConfig(proj)
+	if err != nil {
+		return fail(exitInput, "loading first exam: %v", err)
+	}
+	retake, err := grades.NewExamFromConfig(retakeProj)
+	if err != nil {
+		return fail(exitInput, "loading retake: %v", err)
+	}
+	results, err := merger.Merge(first, retake)
+	if err != nil {
+		return fail(exitValidation, "%v", err)
+	}
+	fmt.Println(results)
+
+	if flags.SaveCSV() {
+		newpathRetake := proj.OutputPath(project.OutputRetake)
+		if err := prepareOutputs([]string{newpathRetake}, flags.Force()); err != nil {
+			return fail(exitOutput, "writing CSV: %v", err)
+		}
+		if err := results.Table().ToCSV(newpathRetake); err != nil {
+			return fail(exitOutput, "writing CSV: %v", err)
+		}
+		if !flags.Quiet() {
+			fmt.Printf("Merged attempts saved as %s.\n", newpathRetake)
+		}
+	}
+	if ignored := results.NotAllowed(); len(ignored) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d students took the retake although they passed the first attempt; their retake was ignored.\n", len(ignored))
+	}
+	return exitOK
+}
+
+func runCourse(flags options) int {
+	if !validOutputFormat(flags.Format()) {
+		return fail(exitUsage, "unknown output format %q", flags.Format())
+	}
+	c, err := project.LoadCourse(flags.Args()[0])
+	if errors.Is(err, project.ErrInvalid) {
+		return fail(exitValidation, "%v", err)
+	}
+	if err != nil {
+		return fail(exitInput, "loading course: %v", err)
+	}
+
+	gradeSet, err := grades.NewGradeSet(c.AllowedGrades(), c.GradeMapping())
+	if err != nil {
+		return fail(exitValidation, "%v", err)
+	}
+
+	course := grades.NewCourse(c.Name()).SetGradeSet(gradeSet)
+	for _, comp := range c.Components() {
+		exam, err := grades.NewExamFromConfig(comp.Project())
+		if err != nil {
+			return fail(exitInput, "loading component %q: %v", comp.Name(), err)
+		}
+		course.AddComponent(comp.Name(), comp.Weight(), comp.MustPass(), exam)
+	}
+
+	if strings.TrimSpace(flags.OutFile()) == "" {
+		err = writeFormatted(os.Stdout, flags.Format(), course, course.GradedTable())
+	} else if err = prepareOutputs([]string{flags.OutFile()}, flags.Force()); err == nil {
+		err = utilities.WriteFileAtomic(flags.OutFile(), func(w io.Writer) error {
+			return writeFormatted(w, flags.Format(), course, course.GradedTable())
+		})
+	}
+	if err != nil {
+		return fail(exitOutput, "writing course grades: %v", err)
+	}
+	if strings.TrimSpace(flags.OutFile()) != "" && !flags.Quiet() {
+		fmt.Printf("Course grades saved as %s.\n", flags.OutFile())
+	}
+	return exitOK
+}
+
+func runGUI(flags options, proj project.Project) int {
+	savedSettings := strings.TrimSpace(flags.ProjectFile()) == ""
+	for _, name := range []string{"pmax", "ppass", "step", "rounding", "granularity"} {
+		savedSettings = savedSettings && !flags.IsSet(name)
+	}
+	if err := gui.ShowExamTables(proj, savedSettings); err != nil {
+		return fail(exitInput, "showing GUI: %v", err)
+	}
+	return exitOK
+}
+
+func validOutputFormat(format string) bool {
+	switch format {
+	case cli.OutputFormatTable, cli.OutputFormatCSV, cli.OutputFormatJSON:
+		return true
+	}
+	return false
+}
+
+func writeFormatted(w io.Writer, format string, text fmt.Stringer, table *utilities.Table) error {
+	switch format {
+	case cli.OutputFormatCSV:
+		return utilities.WriteCSVTo(w, *table)
+	case cli.OutputFormatJSON:
+		return utilities.WriteJSONTo(w, *table)
+	}
+	_, err := fmt.Fprint(w, text)
+	return err
+}
+
+func prepareOutputs(paths []string, force bool) error {
+	if existing := utilities.ExistingFiles(paths); len(existing) > 0 && !force {
+		return fmt.Errorf("refusing to overwrite %s (use --force)", strings.Join(existing, ", "))
+	}
+	for _, path := range paths {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("create output folder: %w", err)
 		}
-		fmt.Printf("Exam data saved as CSV files.\n")
 	}
+	return nil
 }
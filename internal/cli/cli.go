@@ -3,28 +3,261 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/grades"
+	"github.com/andreaswillibaldweber/gogrades/internal/project"
+)
+
+const (
+	CommandKey       = "key"
+	CommandGrade     = "grade"
+	CommandStats     = "stats"
+	CommandExport    = "export"
+	CommandValidate  = "validate"
+	CommandGUI       = "gui"
+	CommandDiff      = "diff"
+	CommandCorrect   = "correct"
+	CommandLog       = "corrections"
+	CommandReconcile = "reconcile"
+	CommandCourse    = "course"
+	CommandRetake    = "retake"
+)
+
+const (
+	ExportFormatHIS     = "his"
+	ExportFormatHISXLSX = "his-xlsx"
+	ExportFormatHTML    = "html"
+	ExportFormatPDF     = "pdf"
+)
+
+const (
+	OutputFormatTable = "table"
+	OutputFormatCSV   = "csv"
+	OutputFormatJSON  = "json"
 )
 
+type command struct {
+	name        string
+	usage       string
+	description string
+	requiresCSV bool
+	args        int
+	register    func(fs *flag.FlagSet, f *flags)
+}
+
+var commands = []command{
+	{
+		name:        CommandKey,
+		usage:       "key [flags] [project]",
+		description: "show the grading key",
+		register: func(fs *flag.FlagSet, f *flags) {
+			registerPoints(fs, f)
+			fs.StringVar(&f.project, "project", "", "path to exam project file (.toml, .yaml); flags given explicitly override its values")
+		},
+	},
+	{
+		name:        CommandGrade,
+		usage:       "grade [flags] <csvfile|project>",
+		description: "grade students from a CSV file",
+		requiresCSV: true,
+		register: func(fs *flag.FlagSet, f *flags) {
+			registerPoints(fs, f)
+			registerCSVFile(fs, f)
+			registerCorrections(fs, f)
+			fs.BoolVar(&f.gkey, "gkey", false, "also show grading key")
+			fs.BoolVar(&f.saveCSV, "savecsv", false, "save graded students to csvfilepath-graded.csv and grading key to csvfilepath-grading-key.csv")
+			registerOutput(fs, f)
+		},
+	},
+	{
+		name:        CommandStats,
+		usage:       "stats [flags] <csvfile|project>",
+		description: "show exam statistics and grade distribution",
+		requiresCSV: true,
+		register: func(fs *flag.FlagSet, f *flags) {
+			registerPoints(fs, f)
+			registerCSVFile(fs, f)
+			registerCorrections(fs, f)
+		},
+	},
+	{
+		name:        CommandExport,
+		usage:       "export [flags] <csvfile|project>",
+		description: "export grades for upload to the exam administration",
+		requiresCSV: true,
+		register: func(fs *flag.FlagSet, f *flags) {
+			registerPoints(fs, f)
+			registerCSVFile(fs, f)
+			registerCorrections(fs, f)
+			fs.StringVar(&f.format, "format", ExportFormatHIS, "export format (his: HISinOne/FlexNow grade upload, his-xlsx: the same upload as XLSX, html: report with highlighted rows, pdf: A4 grade list for printing)")
+			fs.StringVar(&f.outFile, "out", "", "output file (default csvfilepath-hisinone.csv, csvfilepath-report.html or csvfilepath-print.pdf)")
+			fs.StringVar(&f.examNr, "examnr", "", "exam number for the HISinOne/FlexNow export")
+			fs.StringVar(&f.semester, "semester", "", "semester for the HISinOne/FlexNow export, e.g. 20252")
+			fs.BoolVar(&f.signature, "signature", false, "add signature lines for the examiners to every page of the pdf export")
+			registerHighlight(fs, f)
+			registerOutput(fs, f)
+		},
+	},
+	{
+		name:        CommandValidate,
+		usage:       "validate [flags] <csvfile|project>",
+		description: "check a student CSV file before grading",
+		requiresCSV: true,
+		register: func(fs *flag.FlagSet, f *flags) {
+			fs.Float64Var(&f.pmax, "pmax", 90, "maximum points")
+			fs.Float64Var(&f.ppass, "ppass", 45, "passing points")
+			registerCSVFile(fs, f)
+			fs.StringVar(&f.matRegex, "matregex", "", "regular expression for valid matriculation numbers (default \"^[0-9]+$\")")
+		},
+	},
+	{
+		name:        CommandDiff,
+		usage:       "diff [flags] <old-graded.csv> <new-graded.csv>",
+		description: "compare two graded result files by matriculation number",
+		args:        2,
+		register: func(fs *flag.FlagSet, f *flags) {
+			fs.StringVar(&f.format, "format", OutputFormatTable, "output format (table, csv, json)")
+		},
+	},
+	{
+		name:        CommandCorrect,
+		usage:       "correct [flags] <csvfile|project>",
+		description: "append a point correction to the corrections log",
+		requiresCSV: true,
+		register: func(fs *flag.FlagSet, f *flags) {
+			registerPoints(fs, f)
+			registerCSVFile(fs, f)
+			registerCorrections(fs, f)
+			fs.StringVar(&f.matNr, "mat", "", "matriculation number of the corrected student")
+			fs.Float64Var(&f.points, "points", -1, "new points of the student")
+			fs.StringVar(&f.reason, "reason", "", "reason for the correction, e.g. appeal")
+			fs.StringVar(&f.examiner, "examiner", "", "examiner responsible for the correction")
+		},
+	},
+	{
+		name:        CommandLog,
+		usage:       "corrections [flags] <corrections.csv>",
+		description: "show or export the corrections log for the examination board",
+		args:        1,
+		register: func(fs *flag.FlagSet, f *flags) {
+			fs.StringVar(&f.format, "format", OutputFormatTable, "output format (table, csv, json)")
+			fs.StringVar(&f.outFile, "out", "", "write the log to this file instead of stdout")
+			fs.BoolVar(&f.force, "force", false, "overwrite existing output file")
+		},
+	},
+	{
+		name:        CommandReconcile,
+		usage:       "reconcile [flags] --second <second.csv> <first.csv|project>",
+		description: "combine the points of two independent examiners",
+		requiresCSV: true,
+		register: func(fs *flag.FlagSet, f *flags) {
+			registerPoints(fs, f)
+			registerCSVFile(fs, f)
+			fs.StringVar(&f.second, "second", "", "CSV file with the second examiner's points for the same students")
+			fs.StringVar(&f.rule, "rule", "average", "combination rule for the points (average, min, max, first)")
+			fs.Float64Var(&f.threshold, "threshold", 2.0, "grade difference above which a third examiner is required")
+			fs.BoolVar(&f.saveCSV, "savecsv", false, "save merged graded students to csvfilepath-graded.csv and the reconciliation to csvfilepath-reconciliation.csv")
+			registerOutput(fs, f)
+		},
+	},
+	{
+		name:        CommandRetake,
+		usage:       "retake [flags] --retake <retake.csv|project> <first.csv|project>",
+		description: "merge the first exam and the retake by matriculation number",
+		requiresCSV: true,
+		register: func(fs *flag.FlagSet, f *flags) {
+			registerPoints(fs, f)
+			registerCSVFile(fs, f)
+			fs.StringVar(&f.retake, "retake", "", "CSV or project file of the retake exam (a CSV file is graded with the settings of the first exam)")
+			fs.StringVar(&f.policy, "policy", "best", "which attempt counts (best, latest, failed-only)")
+			fs.BoolVar(&f.saveCSV, "savecsv", false, "save the merged attempts to csvfilepath-retake.csv")
+			registerOutput(fs, f)
+		},
+	},
+	{
+		name:        CommandCourse,
+		usage:       "course [flags] <course.toml>",
+		description: "combine weighted exam components into a course grade",
+		args:        1,
+		register: func(fs *flag.FlagSet, f *flags) {
+			fs.StringVar(&f.format, "format", OutputFormatTable, "output format (table, csv, json)")
+			fs.StringVar(&f.outFile, "out", "", "write the course grades to this file instead of stdout")
+			fs.BoolVar(&f.force, "force", false, "overwrite existing output file")
+		},
+	},
+	{
+		name:        CommandGUI,
+		usage:       "gui [flags] [csvfile|project]",
+		description: "show graphical user interface",
+		register: func(fs *flag.FlagSet, f *flags) {
+			registerPoints(fs, f)
+			registerCSVFile(fs, f)
+			registerCorrections(fs, f)
+			registerHighlight(fs, f)
+		},
+	},
+}
+
 type flags struct {
-	gstud   bool
-	gkey    bool
-	gui     bool
-	pmax    float64
-	ppass   float64
-	csvFile string
-	saveCSV bool
+	command     string
+	args        []string
+	set         map[string]bool
+	project     string
+	gkey        bool
+	pmax        float64
+	ppass       float64
+	csvFile     string
+	saveCSV     bool
+	format      string
+	outFile     string
+	examNr      string
+	semester    string
+	outDir      string
+	template    string
+	force       bool
+	quiet       bool
+	matRegex    string
+	corrections string
+	matNr       string
+	points      float64
+	reason      string
+	examiner    string
+	second      string
+	rule        string
+	threshold   float64
+	retake      string
+	policy      string
+	step        string
+	rounding    string
+	granularity float64
+	allowed     string
+	mapping     string
+	highlight   string
+	topGrade    float64
+	signature   bool
 }
 
-func (f flags) GStud() bool {
-	return f.gstud
+func (f flags) Command() string {
+	return f.command
 }
 
-func (f flags) GKey() bool {
-	return f.gkey
+func (f flags) Args() []string {
+	return f.args
+}
+
+func (f flags) IsSet(name string) bool {
+	return f.set[name]
 }
 
-func (f flags) GUI() bool {
-	return f.gui
+func (f flags) ProjectFile() string {
+	return f.project
+}
+
+func (f flags) GKey() bool {
+	return f.gkey
 }
 
 func (f flags) PMax() float64 {
@@ -43,28 +276,265 @@ func (f flags) SaveCSV() bool {
 	return f.saveCSV
 }
 
+func (f flags) Format() string {
+	return f.format
+}
+
+func (f flags) OutFile() string {
+	return f.outFile
+}
+
+func (f flags) ExamNr() string {
+	return f.examNr
+}
+
+func (f flags) Semester() string {
+	return f.semester
+}
+
+func (f flags) OutDir() string {
+	return f.outDir
+}
+
+func (f flags) Template() string {
+	return f.template
+}
+
+func (f flags) Force() bool {
+	return f.force
+}
+
+func (f flags) Quiet() bool {
+	return f.quiet
+}
+
+func (f flags) MatRegex() string {
+	return f.matRegex
+}
+
+func (f flags) CorrectionsFile() string {
+	return f.corrections
+}
+
+func (f flags) MatNr() string {
+	return f.matNr
+}
+
+func (f flags) Points() float64 {
+	return f.points
+}
+
+func (f flags) Reason() string {
+	return f.reason
+}
+
+func (f flags) Examiner() string {
+	return f.examiner
+}
+
+func (f flags) SecondFile() string {
+	return f.second
+}
+
+func (f flags) Rule() string {
+	return f.rule
+}
+
+func (f flags) Threshold() float64 {
+	return f.threshold
+}
+
+func (f flags) RetakeFile() string {
+	return f.retake
+}
+
+func (f flags) Policy() string {
+	return f.policy
+}
+
+func (f flags) Step() string {
+	return f.step
+}
+
+func (f flags) Rounding() string {
+	return f.rounding
+}
+
+func (f flags) Granularity() float64 {
+	return f.granularity
+}
+
+func (f flags) AllowedGrades() string {
+	return f.allowed
+}
+
+func (f flags) GradeMapping() string {
+	return f.mapping
+}
+
+func (f flags) Highlights() string {
+	return f.highlight
+}
+
+func (f flags) TopGrade() float64 {
+	return f.topGrade
+}
+
+func (f flags) Signature() bool {
+	return f.signature
+}
+
 func (f flags) String() string {
-	return fmt.Sprintf("pmax: %v, ppass: %v, csvFile: %s, saveCSV: %t, gkey: %t, gstud: %t, gui: %t", f.pmax, f.ppass, f.csvFile, f.saveCSV, f.gkey, f.gstud, f.gui)
+	return fmt.Sprintf("command: %s, args: %q, project: %s, pmax: %v, ppass: %v, csvFile: %s, saveCSV: %t, gkey: %t, format: %s, outFile: %s, examNr: %s, semester: %s, outDir: %s, template: %s, force: %t, quiet: %t, matRegex: %s, corrections: %s, second: %s, rule: %s, threshold: %v, retake: %s, policy: %s, step: %s, rounding: %s, granularity: %v, allowed: %s, mapping: %s, highlight: %s, topgrade: %v, signature: %t", f.command, f.args, f.project, f.pmax, f.ppass, f.csvFile, f.saveCSV, f.gkey, f.format, f.outFile, f.examNr, f.semester, f.outDir, f.template, f.force, f.quiet, f.matRegex, f.corrections, f.second, f.rule, f.threshold, f.retake, f.policy, f.step, f.rounding, f.granularity, f.allowed, f.mapping, f.highlight, f.topGrade, f.signature)
 }
 
-func ParseFlags() flags {
-	gkey := flag.Bool("gkey", false, "show grading key")
-	gstud := flag.Bool("gstud", false, "show graded students")
-	gui := flag.Bool("gui", false, "show graphical user interface")
-	pmax := flag.Float64("pmax", 90, "maximum points")
-	ppass := flag.Float64("ppass", 45, "passing points")
-	csvFile := flag.String("csvfile", "", "path to CSV file with student data")
-	saveCSV := flag.Bool("savecsv", false, "path to save CSV file with student data (overwrites existing file)")
+func ParseFlags() (flags, error) {
+	return Parse(os.Args[1:], os.Stderr)
+}
+
+func Parse(args []string, output io.Writer) (flags, error) {
+	if len(args) == 0 {
+		Usage(output)
+		return flags{}, fmt.Errorf("missing command")
+	}
+
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" || name == "-help" {
+		Usage(output)
+		return flags{}, flag.ErrHelp
+	}
 
-	flag.Parse()
+	cmd, ok := findCommand(name)
+	if !ok {
+		Usage(output)
+		return flags{}, fmt.Errorf("unknown command %q", name)
+	}
 
-	return flags{
-		gstud:   *gstud,
-		gkey:    *gkey,
-		gui:     *gui,
-		pmax:    *pmax,
-		ppass:   *ppass,
-		csvFile: *csvFile,
-		saveCSV: *saveCSV,
+	f := flags{command: cmd.name}
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gogrades %s\n\n%s\n\nFlags:\n", cmd.usage, cmd.description)
+		fs.PrintDefaults()
+	}
+	cmd.register(fs, &f)
+	fs.BoolVar(&f.quiet, "quiet", false, "only print results and errors")
+
+	positional, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return flags{}, err
+	}
+	f.set = make(map[string]bool)
+	fs.Visit(func(fl *flag.Flag) {
+		f.set[fl.Name] = true
+	})
+	if cmd.args > 0 {
+		if len(positional) != cmd.args {
+			fs.Usage()
+			return flags{}, fmt.Errorf("%q expects %d arguments, got %d", cmd.name, cmd.args, len(positional))
+		}
+		f.args = positional
+		return f, nil
+	}
+	if err := f.applyArgs(positional); err != nil {
+		fs.Usage()
+		return flags{}, err
 	}
+	if cmd.requiresCSV && strings.TrimSpace(f.csvFile) == "" && strings.TrimSpace(f.project) == "" {
+		fs.Usage()
+		return flags{}, fmt.Errorf("a CSV or project file is required for %q", cmd.name)
+	}
+	return f, nil
+}
+
+func Usage(output io.Writer) {
+	fmt.Fprintf(output, "Usage: gogrades <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(output, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintf(output, "\nRun 'gogrades <command> -h' for the flags of a command.\n")
+}
+
+func (f *flags) applyArgs(args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(args[1:], " "))
+	}
+	if f.command == CommandKey && !project.IsProjectFile(args[0]) {
+		return fmt.Errorf("unexpected argument: %s", args[0])
+	}
+	if project.IsProjectFile(args[0]) {
+		if f.project != "" {
+			return fmt.Errorf("project file given twice (--project and argument)")
+		}
+		f.project = args[0]
+		return nil
+	}
+	if f.csvFile != "" {
+		return fmt.Errorf("csv file given twice (--csvfile and argument)")
+	}
+	f.csvFile = args[0]
+	return nil
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// parseInterspersed parses flags before and after the positional arguments,
+// so that "course course.toml --quiet" works like "course --quiet course.toml".
+// Everything after "--" is positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0)
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+func registerPoints(fs *flag.FlagSet, f *flags) {
+	fs.Float64Var(&f.pmax, "pmax", 90, "maximum points")
+	fs.Float64Var(&f.ppass, "ppass", 45, "passing points")
+	fs.StringVar(&f.step, "step", grades.DefaultStep, "grade steps (thirds: 1.0, 1.3, 1.7, ...; tenths; halves; whole)")
+	fs.StringVar(&f.rounding, "rounding", grades.DefaultRounding, "rounding to the grade steps (nearest, favorable: always the better grade)")
+	fs.Float64Var(&f.granularity, "granularity", grades.DefaultGranularity, "point increments of the grading key (0.25, 0.5, 1)")
+	fs.StringVar(&f.allowed, "allowed", "", "comma separated list of allowed grades, e.g. \"1.0,1.3,1.7,2.0,2.3,2.7,3.0,3.3,3.7,4.0\" (default: all)")
+	fs.StringVar(&f.mapping, "mapping", grades.DefaultGradeMapping, "mapping of grades outside --allowed (better, worse, nearest)")
+}
+
+func registerCSVFile(fs *flag.FlagSet, f *flags) {
+	fs.StringVar(&f.csvFile, "csvfile", "", "path to CSV file with student data")
+	fs.StringVar(&f.project, "project", "", "path to exam project file (.toml, .yaml); flags given explicitly override its values")
+}
+
+func registerOutput(fs *flag.FlagSet, f *flags) {
+	fs.StringVar(&f.outDir, "outdir", "", "folder for output files (default: folder of the student file)")
+	fs.StringVar(&f.template, "template", "", "output file name template with {base}, {kind}, {course} and {date} (default \"{base}-{kind}.csv\")")
+	fs.BoolVar(&f.force, "force", false, "overwrite existing output files")
+}
+
+func registerHighlight(fs *flag.FlagSet, f *flags) {
+	fs.StringVar(&f.highlight, "highlight", strings.Join(grades.DefaultHighlights, ","), "comma separated highlight rules (failed, top, above-max, duplicate, missing-seat, none)")
+	fs.Float64Var(&f.topGrade, "topgrade", grades.DefaultTopGrade, "grades up to this value are highlighted as top grades")
+}
+
+func registerCorrections(fs *flag.FlagSet, f *flags) {
+	fs.StringVar(&f.corrections, "corrections", "", "path to corrections log applied on top of the imported points")
 }
@@ -0,0 +1,42 @@
+package grades
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+type bonus map[string]float64
+
+func NewBonus() bonus {
+	return make(bonus)
+}
+
+func NewBonusFromTable(table *utilities.Table) (bonus, error) {
+	b := NewBonus()
+	for _, row := range table.Rows() {
+		if len(row) < 2 {
+			return NewBonus(), fmt.Errorf("invalid bonus row: expected 2 columns (matNr, bonus), got %d", len(row))
+		}
+		matNr := fmt.Sprintf("%v", row[0])
+		points, err := strconv.ParseFloat(fmt.Sprintf("%v", row[1]), 64)
+		if err != nil {
+			return NewBonus(), fmt.Errorf("parse bonus for %q: %v", matNr, err)
+		}
+		b[matNr] += points
+	}
+	return b, nil
+}
+
+func NewBonusFromCSV(filepath string) (bonus, error) {
+	table, err := utilities.ReadPlainCSV(filepath)
+	if err != nil {
+		return NewBonus(), fmt.Errorf("read bonus CSV: %w", err)
+	}
+	return NewBonusFromTable(table)
+}
+
+func (b bonus) Points(matNr string) float64 {
+	return b[matNr]
+}
@@ -0,0 +1,83 @@
+package grades
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+// CompareGradedTables joins several graded tables by matriculation number,
+// e.g. the first exam and the retake or the exams of different courses. A
+// student missing in an exam gets empty cells for it. Exams that cannot be
+// joined, e.g. because of a duplicate matriculation number, are left out and
+// the reason is returned as a note.
+func CompareGradedTables(names []string, tables []*utilities.Table) (*utilities.Table, []string, error) {
+	if len(names) != len(tables) {
+		return nil, nil, fmt.Errorf("%d names for %d graded tables", len(names), len(tables))
+	}
+
+	exams := make([]map[string]gradedRow, 0, len(tables))
+	joined := make([]string, 0, len(names))
+	notes := make([]string, 0)
+	matNrs := make([]string, 0)
+	seen := make(map[string]bool)
+	for i, table := range tables {
+		rows, err := gradedRowsFromTable(table)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%s left out: %v", names[i], err))
+			continue
+		}
+		exams = append(exams, rows)
+		joined = append(joined, names[i])
+		for matNr := range rows {
+			if !seen[matNr] {
+				seen[matNr] = true
+				matNrs = append(matNrs, matNr)
+			}
+		}
+	}
+	sort.Strings(matNrs)
+	names = joined
+
+	header := []string{"Mat", "Student Name"}
+	hooks := make(map[int]utilities.FormatHook)
+	for _, name := range names {
+		hooks[len(header)] = utilities.BuildDecimalFormatHook(1)
+		hooks[len(header)+1] = utilities.BuildDecimalFormatHook(1)
+		header = append(header, name+" Points", name+" Grade")
+	}
+	hooks[len(header)] = utilities.BuildDecimalFormatHook(1)
+	header = append(header, "Best Grade", "Passed In")
+
+	rows := make([]utilities.TableRow, 0, len(matNrs))
+	for _, matNr := range matNrs {
+		row := utilities.TableRow{matNr, ""}
+		best := 0.0
+		passed := make([]string, 0)
+		for i, exam := range exams {
+			r, ok := exam[matNr]
+			if !ok {
+				row = append(row, "", "")
+				continue
+			}
+			if row[1] == "" {
+				row[1] = r.name
+			}
+			row = append(row, r.points, r.grade)
+			if best == 0 || r.grade < best {
+				best = r.grade
+			}
+			if r.grade <= 4.0 {
+				passed = append(passed, names[i])
+			}
+		}
+		row = append(row, best, strings.Join(passed, ", "))
+		rows = append(rows, row)
+	}
+
+	table := utilities.NewTable(header, rows)
+	table.SetFormatHooks(hooks)
+	return table, notes, nil
+}
@@ -0,0 +1,137 @@
+package grades
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+var correctionsHeader = []string{"Mat-Nr", "Old Points", "New Points", "Reason", "Examiner", "Timestamp"}
+
+type correction struct {
+	matNr     string
+	oldPoints float64
+	newPoints float64
+	reason    string
+	examiner  string
+	timestamp time.Time
+}
+
+func NewCorrection(matNr string, oldPoints, newPoints float64, reason, examiner string, timestamp time.Time) correction {
+	return correction{
+		matNr:     matNr,
+		oldPoints: oldPoints,
+		newPoints: newPoints,
+		reason:    reason,
+		examiner:  examiner,
+		timestamp: timestamp,
+	}
+}
+
+func (c correction) MatNr() string {
+	return c.matNr
+}
+
+func (c correction) NewPoints() float64 {
+	return c.newPoints
+}
+
+func (c correction) record() []string {
+	return []string{
+		c.matNr,
+		strconv.FormatFloat(c.oldPoints, 'f', -1, 64),
+		strconv.FormatFloat(c.newPoints, 'f', -1, 64),
+		c.reason,
+		c.examiner,
+		c.timestamp.Format(time.RFC3339),
+	}
+}
+
+func (c correction) String() string {
+	return fmt.Sprintf(
+		"Correction{matNr: %q, oldPoints: %.2f, newPoints: %.2f, reason: %q, examiner: %q, timestamp: %s}",
+		c.matNr, c.oldPoints, c.newPoints, c.reason, c.examiner, c.timestamp.Format(time.RFC3339),
+	)
+}
+
+type corrections []correction
+
+func NewCorrections() corrections {
+	return corrections{}
+}
+
+func NewCorrectionsFromTable(table *utilities.Table) (corrections, error) {
+	c := NewCorrections()
+	for i, row := range table.Rows() {
+		if len(row) < len(correctionsHeader) {
+			return NewCorrections(), fmt.Errorf("invalid correction %d: expected %d columns, got %d", i+1, len(correctionsHeader), len(row))
+		}
+		cell := func(idx int) string {
+			return fmt.Sprintf("%v", row[idx])
+		}
+		oldPoints, err := strconv.ParseFloat(cell(1), 64)
+		if err != nil {
+			return NewCorrections(), fmt.Errorf("parse old points of correction %d: %v", i+1, err)
+		}
+		newPoints, err := strconv.ParseFloat(cell(2), 64)
+		if err != nil {
+			return NewCorrections(), fmt.Errorf("parse new points of correction %d: %v", i+1, err)
+		}
+		timestamp, err := time.Parse(time.RFC3339, cell(5))
+		if err != nil {
+			return NewCorrections(), fmt.Errorf("parse timestamp of correction %d: %v", i+1, err)
+		}
+		c = append(c, NewCorrection(cell(0), oldPoints, newPoints, cell(3), cell(4), timestamp))
+	}
+	return c, nil
+}
+
+// NewCorrectionsFromCSV reads a corrections log. A missing log is treated as
+// empty, so the first correction can create it.
+func NewCorrectionsFromCSV(filepath string) (corrections, error) {
+	table, err := utilities.ReadPlainCSV(filepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewCorrections(), nil
+	}
+	if err != nil {
+		return NewCorrections(), fmt.Errorf("read corrections log: %w", err)
+	}
+	return NewCorrectionsFromTable(table)
+}
+
+func AppendCorrectionCSV(filepath string, c correction) error {
+	if err := utilities.AppendCSVRow(filepath, correctionsHeader, c.record()); err != nil {
+		return fmt.Errorf("append to corrections log: %w", err)
+	}
+	return nil
+}
+
+func (c corrections) Table() *utilities.Table {
+	rows := make([]utilities.TableRow, 0)
+	for _, correction := range c {
+		rows = append(rows, utilities.TableRow{
+			correction.matNr, correction.oldPoints, correction.newPoints,
+			correction.reason, correction.examiner, correction.timestamp.Format(time.RFC3339),
+		})
+	}
+	hooks := map[int]utilities.FormatHook{
+		1: utilities.BuildDecimalFormatHook(1),
+		2: utilities.BuildDecimalFormatHook(1),
+	}
+	header := make([]string, len(correctionsHeader))
+	copy(header, correctionsHeader)
+	table := utilities.NewTable(header, rows)
+	table.SetFormatHooks(hooks)
+	return table
+}
+
+func (c corrections) String() string {
+	return fmt.Sprintf(
+		"Corrections log with %d entries:\n%s", len(c),
+		c.Table().FormatTableRight([]int{1, 2}),
+	)
+}
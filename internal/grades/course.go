@@ -0,0 +1,162 @@
+package grades
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+const failedGrade = 5.0
+
+type component struct {
+	name     string
+	weight   float64
+	mustPass bool
+	exam     exam
+}
+
+type course struct {
+	name       string
+	components []component
+	gradeSet   gradeSet
+}
+
+func NewCourse(name string) *course {
+	return &course{name: name, components: make([]component, 0), gradeSet: gradeSet{mapping: DefaultGradeMapping}}
+}
+
+// SetGradeSet restricts the final grades to the allowed grades of the course.
+func (c *course) SetGradeSet(g gradeSet) *course {
+	c.gradeSet = g
+	return c
+}
+
+func (c *course) AddComponent(name string, weight float64, mustPass bool, e exam) *course {
+	c.components = append(c.components, component{name: name, weight: weight, mustPass: mustPass, exam: e})
+	return c
+}
+
+type courseResult struct {
+	name    string
+	matNr   string
+	grades  []float64
+	present []bool
+	final   float64
+	status  string
+}
+
+func (r courseResult) Final() float64 {
+	return r.final
+}
+
+// Results combines the components by matriculation number. The final grade is
+// the weighted mean of the component grades, truncated after the first
+// decimal and mapped into the grade set of the course. A missing component
+// fails the whole course, just like a failed component marked mustPass.
+func (c course) Results() []courseResult {
+	results := make(map[string]*courseResult)
+	order := make([]string, 0)
+
+	for compIdx, comp := range c.components {
+		for _, s := range comp.exam.students {
+			r, ok := results[s.matNr]
+			if !ok {
+				r = &courseResult{
+					name:    s.name,
+					matNr:   s.matNr,
+					grades:  make([]float64, len(c.components)),
+					present: make([]bool, len(c.components)),
+				}
+				results[s.matNr] = r
+				order = append(order, s.matNr)
+			}
+			r.grades[compIdx] = comp.exam.Grade(s)
+			r.present[compIdx] = true
+		}
+	}
+
+	out := make([]courseResult, 0, len(order))
+	for _, matNr := range order {
+		r := results[matNr]
+		c.finish(r)
+		out = append(out, *r)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].matNr < out[j].matNr
+	})
+	return out
+}
+
+func (c course) finish(r *courseResult) {
+	missing := make([]string, 0)
+	failed := make([]string, 0)
+	sum, weights := 0.0, 0.0
+	for i, comp := range c.components {
+		grade := r.grades[i]
+		if !r.present[i] {
+			grade = failedGrade
+			r.grades[i] = grade
+			missing = append(missing, comp.name)
+		}
+		if comp.mustPass && grade > 4.0 {
+			failed = append(failed, comp.name)
+		}
+		sum += comp.weight * grade
+		weights += comp.weight
+	}
+
+	r.final = math.Floor(10*sum/weights+1e-9) / 10
+	switch {
+	case len(failed) > 0:
+		r.final = failedGrade
+		r.status = "failed: " + strings.Join(failed, ", ")
+	case len(missing) > 0 || r.final > 4.0:
+		r.final = failedGrade
+		r.status = "failed"
+	default:
+		r.final = c.gradeSet.Map(r.final)
+		r.status = "passed"
+	}
+	if len(missing) > 0 {
+		r.status += " (missing: " + strings.Join(missing, ", ") + ")"
+	}
+}
+
+func (c course) GradedTable() *utilities.Table {
+	header := []string{"Student Name", "Mat"}
+	hooks := make(map[int]utilities.FormatHook)
+	for _, comp := range c.components {
+		hooks[len(header)] = utilities.BuildDecimalFormatHook(1)
+		header = append(header, fmt.Sprintf("%s (%g)", comp.name, comp.weight))
+	}
+	hooks[len(header)] = utilities.BuildDecimalFormatHook(1)
+	header = append(header, "Final Grade", "Status")
+
+	rows := make([]utilities.TableRow, 0)
+	for _, r := range c.Results() {
+		row := utilities.TableRow{r.name, r.matNr}
+		for _, grade := range r.grades {
+			row = append(row, grade)
+		}
+		row = append(row, r.final, r.status)
+		rows = append(rows, row)
+	}
+	table := utilities.NewTable(header, rows)
+	table.SetFormatHooks(hooks)
+	return table
+}
+
+func (c course) String() string {
+	rightAlign := make([]int, 0)
+	for i := range c.components {
+		rightAlign = append(rightAlign, 2+i)
+	}
+	rightAlign = append(rightAlign, 2+len(c.components))
+	return fmt.Sprintf(
+		"Course %q with %d components:\n%s", c.name, len(c.components),
+		c.GradedTable().FormatTableRight(rightAlign),
+	)
+}
@@ -0,0 +1,159 @@
+package grades
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+const (
+	DiffPoints  = "points changed"
+	DiffGrade   = "grade changed"
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+)
+
+type gradedRow struct {
+	name   string
+	matNr  string
+	points float64
+	grade  float64
+}
+
+type gradeChange struct {
+	status string
+	old    gradedRow
+	new    gradedRow
+}
+
+func (c gradeChange) MatNr() string {
+	if c.status == DiffRemoved {
+		return c.old.matNr
+	}
+	return c.new.matNr
+}
+
+func (c gradeChange) Name() string {
+	if c.status == DiffRemoved {
+		return c.old.name
+	}
+	return c.new.name
+}
+
+type gradeDiff []gradeChange
+
+func DiffGradedCSV(oldPath, newPath string) (gradeDiff, error) {
+	oldTable, err := utilities.ReadPlainCSV(oldPath)
+	if err != nil {
+		return gradeDiff{}, fmt.Errorf("read %s: %w", oldPath, err)
+	}
+	newTable, err := utilities.ReadPlainCSV(newPath)
+	if err != nil {
+		return gradeDiff{}, fmt.Errorf("read %s: %w", newPath, err)
+	}
+	return DiffGradedTables(oldTable, newTable)
+}
+
+func DiffGradedTables(oldTable, newTable *utilities.Table) (gradeDiff, error) {
+	oldRows, err := gradedRowsFromTable(oldTable)
+	if err != nil {
+		return gradeDiff{}, fmt.Errorf("old graded table: %w", err)
+	}
+	newRows, err := gradedRowsFromTable(newTable)
+	if err != nil {
+		return gradeDiff{}, fmt.Errorf("new graded table: %w", err)
+	}
+
+	diff := make(gradeDiff, 0)
+	for matNr, n := range newRows {
+		o, ok := oldRows[matNr]
+		if !ok {
+			diff = append(diff, gradeChange{status: DiffAdded, new: n})
+			continue
+		}
+		if o.grade != n.grade {
+			diff = append(diff, gradeChange{status: DiffGrade, old: o, new: n})
+		} else if o.points != n.points {
+			diff = append(diff, gradeChange{status: DiffPoints, old: o, new: n})
+		}
+	}
+	for matNr, o := range oldRows {
+		if _, ok := newRows[matNr]; !ok {
+			diff = append(diff, gradeChange{status: DiffRemoved, old: o})
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool {
+		return diff[i].MatNr() < diff[j].MatNr()
+	})
+	return diff, nil
+}
+
+func gradedRowsFromTable(table *utilities.Table) (map[string]gradedRow, error) {
+	nameIdx := table.ColumnIndex("Student Name")
+	matIdx := table.ColumnIndex("Mat")
+	pointsIdx := table.ColumnIndex("Points")
+	gradeIdx := table.ColumnIndex("Grade")
+	if matIdx < 0 || pointsIdx < 0 || gradeIdx < 0 {
+		return nil, fmt.Errorf("missing column: expected graded table with Mat, Points and Grade columns")
+	}
+
+	rows := make(map[string]gradedRow)
+	for _, row := range table.Rows() {
+		cell := func(idx int) string {
+			if idx < 0 || idx >= len(row) {
+				return ""
+			}
+			return fmt.Sprintf("%v", row[idx])
+		}
+		matNr := cell(matIdx)
+		if _, ok := rows[matNr]; ok {
+			return nil, fmt.Errorf("duplicate matriculation number %q", matNr)
+		}
+		points, err := strconv.ParseFloat(cell(pointsIdx), 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse points for %q: %v", matNr, err)
+		}
+		grade, err := strconv.ParseFloat(cell(gradeIdx), 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse grade for %q: %v", matNr, err)
+		}
+		rows[matNr] = gradedRow{name: cell(nameIdx), matNr: matNr, points: points, grade: grade}
+	}
+	return rows, nil
+}
+
+func (d gradeDiff) Table() *utilities.Table {
+	header := []string{"Status", "Mat", "Student Name", "Old Points", "New Points", "Old Grade", "New Grade"}
+	rows := make([]utilities.TableRow, 0)
+	for _, c := range d {
+		var oldPoints, newPoints, oldGrade, newGrade any = "", "", "", ""
+		if c.status != DiffAdded {
+			oldPoints, oldGrade = c.old.points, c.old.grade
+		}
+		if c.status != DiffRemoved {
+			newPoints, newGrade = c.new.points, c.new.grade
+		}
+		rows = append(rows, utilities.TableRow{c.status, c.MatNr(), c.Name(), oldPoints, newPoints, oldGrade, newGrade})
+	}
+	hooks := map[int]utilities.FormatHook{
+		3: utilities.BuildDecimalFormatHook(1),
+		4: utilities.BuildDecimalFormatHook(1),
+		5: utilities.BuildDecimalFormatHook(1),
+		6: utilities.BuildDecimalFormatHook(1),
+	}
+	table := utilities.NewTable(header, rows)
+	table.SetFormatHooks(hooks)
+	return table
+}
+
+func (d gradeDiff) String() string {
+	if len(d) == 0 {
+		return "No differences.\n"
+	}
+	return fmt.Sprintf(
+		"%d students differ:\n%s", len(d),
+		d.Table().FormatTableRight([]int{3, 4, 5, 6}),
+	)
+}
@@ -3,6 +3,7 @@ package grades
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
 )
@@ -11,6 +12,29 @@ type exam struct {
 	pMax     float64
 	pPass    float64
 	students students
+	bonus    bonus
+	changed  map[string]bool
+	rounding rounding
+	gradeSet gradeSet
+}
+
+type ExamConfig interface {
+	GradingConfig
+	PMax() float64
+	PPass() float64
+	StudentFile() string
+	BonusFile() string
+	CorrectionsFile() string
+}
+
+// GradingConfig holds the settings that turn points into grades besides the
+// point limits.
+type GradingConfig interface {
+	Step() string
+	Rounding() string
+	Granularity() float64
+	AllowedGrades() []float64
+	GradeMapping() string
 }
 
 func NewExam(pMax, pPass float64) exam {
@@ -18,9 +42,54 @@ func NewExam(pMax, pPass float64) exam {
 		pMax:     pMax,
 		pPass:    pPass,
 		students: make(students, 0),
+		bonus:    NewBonus(),
+		changed:  make(map[string]bool),
+		rounding: defaultRounding(),
+		gradeSet: gradeSet{mapping: DefaultGradeMapping},
 	}
 }
 
+func NewExamFromConfig(cfg ExamConfig) (exam, error) {
+	e, err := NewExamFromCSV(cfg.PMax(), cfg.PPass(), cfg.StudentFile())
+	if err != nil {
+		return e, err
+	}
+	if err := e.Configure(cfg); err != nil {
+		return e, err
+	}
+	if cfg.BonusFile() != "" {
+		b, err := NewBonusFromCSV(cfg.BonusFile())
+		if err != nil {
+			return e, err
+		}
+		e.SetBonus(b)
+	}
+	if cfg.CorrectionsFile() != "" {
+		c, err := NewCorrectionsFromCSV(cfg.CorrectionsFile())
+		if err != nil {
+			return e, err
+		}
+		if err := e.ApplyCorrections(c); err != nil {
+			return e, err
+		}
+	}
+	return e, nil
+}
+
+func NewExamFromCSV(pMax, pPass float64, filepath string) (exam, error) {
+	e := NewExam(pMax, pPass)
+	table, err := utilities.NewTableFromCSV(filepath)
+	if err != nil {
+		return e, fmt.Errorf("read CSV: %w", err)
+	}
+	students, err := NewStudentsFromTable(table)
+	if err != nil {
+		return e, fmt.Errorf("parse students: %w", err)
+	}
+	e.AddStudents(students)
+	return e, nil
+}
+
 func (e exam) Students() *students {
 	return &e.students
 }
@@ -35,13 +104,112 @@ func (e *exam) AddStudents(students *students) *students {
 	return &e.students
 }
 
-func (e *exam) DeleteStudent(matNr string) {
+func (e *exam) SetBonus(b bonus) {
+	e.bonus = b
+}
+
+func (e *exam) SetRounding(r rounding) {
+	e.rounding = r
+}
+
+func (e exam) Rounding() rounding {
+	return e.rounding
+}
+
+func (e *exam) SetGradeSet(g gradeSet) {
+	e.gradeSet = g
+}
+
+func (e exam) GradeSet() gradeSet {
+	return e.gradeSet
+}
+
+// Configure applies rounding and grade restrictions to the exam.
+func (e *exam) Configure(cfg GradingConfig) error {
+	r, err := NewRounding(cfg.Step(), cfg.Rounding(), cfg.Granularity())
+	if err != nil {
+		return err
+	}
+	g, err := NewGradeSet(cfg.AllowedGrades(), cfg.GradeMapping())
+	if err != nil {
+		return err
+	}
+	e.SetRounding(r)
+	e.SetGradeSet(g)
+	return nil
+}
+
+func (e exam) PMax() float64 {
+	return e.pMax
+}
+
+func (e exam) PPass() float64 {
+	return e.pPass
+}
+
+func (e exam) Points(s student) float64 {
+	return s.Points() + e.bonus.Points(s.matNr)
+}
+
+func (e exam) findStudent(matNr string) int {
 	for i, s := range e.students {
 		if s.matNr == matNr {
-			e.students = append(e.students[:i], e.students[i+1:]...)
-			return
+			return i
 		}
 	}
+	return -1
+}
+
+// duplicateMatNr returns the first matriculation number that occurs more
+// than once; findStudent would silently pick the first of them.
+func (e exam) duplicateMatNr() (string, bool) {
+	seen := make(map[string]bool, len(e.students))
+	for _, s := range e.students {
+		if seen[s.matNr] {
+			return s.matNr, true
+		}
+		seen[s.matNr] = true
+	}
+	return "", false
+}
+
+func (e exam) Correct(matNr string, newPoints float64, reason, examiner string, timestamp time.Time) (correction, error) {
+	idx := e.findStudent(matNr)
+	if idx < 0 {
+		return correction{}, fmt.Errorf("no student with matriculation number %q", matNr)
+	}
+	if newPoints < 0 {
+		return correction{}, fmt.Errorf("points must be >= 0")
+	}
+	if newPoints > e.pMax {
+		return correction{}, fmt.Errorf("points must be <= %.1f (max points)", e.pMax)
+	}
+	return NewCorrection(matNr, e.students[idx].points, newPoints, reason, examiner, timestamp), nil
+}
+
+// ApplyCorrections replays a corrections log on top of the imported points.
+// Every entry must start from the points the previous entries left behind,
+// otherwise the log does not belong to this import.
+func (e *exam) ApplyCorrections(c corrections) error {
+	for _, corr := range c {
+		idx := e.findStudent(corr.matNr)
+		if idx < 0 {
+			return fmt.Errorf("correction for unknown matriculation number %q", corr.matNr)
+		}
+		if e.students[idx].points != corr.oldPoints {
+			return fmt.Errorf("correction for %q expects %.2f points, found %.2f", corr.matNr, corr.oldPoints, e.students[idx].points)
+		}
+		e.students[idx].points = corr.newPoints
+		if e.changed == nil {
+			e.changed = make(map[string]bool)
+		}
+		e.changed[corr.matNr] = true
+	}
+	return nil
+}
+
+func (e exam) Changed(s student) bool {
+	return e.changed[s.matNr]
 }
 
 func (e exam) AmountStudents() int {
@@ -49,7 +217,7 @@ func (e exam) AmountStudents() int {
 }
 
 func (e exam) Grade(s student) float64 {
-	return e.LinearGrading(s.Points())
+	return e.LinearGrading(e.Points(s))
 }
 
 func (e exam) LinearGrading(points float64) float64 {
@@ -58,10 +226,10 @@ func (e exam) LinearGrading(points float64) float64 {
 		return 5.0
 	}
 	if points > e.pMax {
-		return 1.0
+		return e.gradeSet.Best()
 	}
 
-	return e.gRounded(points)
+	return e.gradeSet.Map(e.gRounded(points))
 }
 
 func (e exam) gRounded(p float64) float64 {
@@ -73,11 +241,11 @@ func (e exam) graw(p float64) float64 {
 }
 
 func (e exam) k(p float64) float64 {
-	return math.Floor(3*(e.graw(p)-1) + 0.5)
+	return e.rounding.steps(e.graw(p) - 1)
 }
 
 func (e exam) gStep(p float64) float64 {
-	return 1 + e.k(p)/3
+	return 1 + e.k(p)/e.rounding.stepsPerGrade()
 }
 
 func (e exam) GradingKeyTable() *utilities.Table {
@@ -91,8 +259,10 @@ func (e exam) GradingKeyTable() *utilities.Table {
 
 	grades := make([]grading, 0)
 	lastGrade := 0.0
-	for i := 0.0; i < e.pMax+0.1; i += 0.5 {
-		if lastGrade != e.LinearGrading(i) || i >= e.pMax-0.2 {
+	step := e.rounding.granularity
+	for n := 0; float64(n)*step <= e.pMax+1e-9; n++ {
+		i := float64(n) * step
+		if lastGrade != e.LinearGrading(i) || i+step > e.pMax+1e-9 {
 			grades = append(grades, grading{points: i, percentage: i / e.pMax * 100, grade: e.LinearGrading(i)})
 			lastGrade = e.LinearGrading(i)
 		}
@@ -104,8 +274,12 @@ func (e exam) GradingKeyTable() *utilities.Table {
 		row := utilities.TableRow{nr, g.points, g.percentage, g.grade}
 		rows = append(rows, row)
 	}
+	pointDecimals := 1
+	if step < 0.5 {
+		pointDecimals = 2
+	}
 	hooks := map[int]utilities.FormatHook{
-		1: utilities.BuildDecimalFormatHook(1),
+		1: utilities.BuildDecimalFormatHook(pointDecimals),
 		2: utilities.BuildPercentageFormatHook(1),
 		3: utilities.BuildDecimalFormatHook(1),
 	}
@@ -124,9 +298,20 @@ func (e exam) GradingKeyString() string {
 
 func (e exam) GradedStudentTable() *utilities.Table {
 	header := []string{"Student Name", "Mat", "Seat", "Points", "%", "Grade", "Comment"}
+	if len(e.changed) > 0 {
+		header = append(header, "Changed")
+	}
 	rows := make([]utilities.TableRow, 0)
 	for _, s := range e.students {
-		row := utilities.TableRow{s.name, s.matNr, s.seatNr, s.points, 100 * s.points / e.pMax, e.Grade(s), s.comment}
+		points := e.Points(s)
+		row := utilities.TableRow{s.name, s.matNr, s.seatNr, points, 100 * points / e.pMax, e.Grade(s), s.comment}
+		if len(e.changed) > 0 {
+			marker := ""
+			if e.Changed(s) {
+				marker = "*"
+			}
+			row = append(row, marker)
+		}
 		rows = append(rows, row)
 	}
 	hooks := map[int]utilities.FormatHook{
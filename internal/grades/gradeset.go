@@ -0,0 +1,139 @@
+package grades
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	MapBetter  = "better"
+	MapWorse   = "worse"
+	MapNearest = "nearest"
+)
+
+const DefaultGradeMapping = MapBetter
+
+// gradeSet restricts the grades an exam may award. Grades outside the set are
+// mapped to an allowed one; an empty set allows every grade.
+type gradeSet struct {
+	allowed []float64
+	mapping string
+}
+
+func NewGradeSet(allowed []float64, mapping string) (gradeSet, error) {
+	switch mapping {
+	case MapBetter, MapWorse, MapNearest:
+	default:
+		return gradeSet{}, fmt.Errorf("unknown grade mapping %q (use %s, %s or %s)", mapping, MapBetter, MapWorse, MapNearest)
+	}
+	if len(allowed) == 0 {
+		return gradeSet{mapping: mapping}, nil
+	}
+
+	set := make([]float64, 0, len(allowed)+1)
+	passing := false
+	for _, grade := range allowed {
+		if grade < 0.7-1e-9 || grade > 5.0+1e-9 {
+			return gradeSet{}, fmt.Errorf("allowed grade %.1f out of range (0.7 to 5.0)", grade)
+		}
+		if grade > 4.0+1e-9 && !sameGrade(grade, failedGrade) {
+			return gradeSet{}, fmt.Errorf("allowed grade %.1f: the only failing grade is 5.0", grade)
+		}
+		if grade <= 4.0+1e-9 {
+			passing = true
+		}
+		set = append(set, grade)
+	}
+	if !passing {
+		return gradeSet{}, fmt.Errorf("allowed grades contain no passing grade")
+	}
+	set = append(set, failedGrade)
+	sort.Float64s(set)
+
+	unique := set[:1]
+	for _, grade := range set[1:] {
+		if !sameGrade(grade, unique[len(unique)-1]) {
+			unique = append(unique, grade)
+		}
+	}
+	return gradeSet{allowed: unique, mapping: mapping}, nil
+}
+
+// ParseGradeList parses a comma separated list of grades such as "1.0,1.3,1.7".
+func ParseGradeList(list string) ([]float64, error) {
+	grades := make([]float64, 0)
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		grade, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid grade %q", field)
+		}
+		grades = append(grades, grade)
+	}
+	return grades, nil
+}
+
+func (g gradeSet) Allowed() []float64 {
+	return g.allowed
+}
+
+func (g gradeSet) Mapping() string {
+	return g.mapping
+}
+
+// Best is the best grade of the set, awarded for points above the maximum.
+func (g gradeSet) Best() float64 {
+	if len(g.allowed) == 0 {
+		return 1.0
+	}
+	return g.allowed[0]
+}
+
+// Map returns the allowed grade for a computed one. A passing grade is never
+// mapped to a failing one, and a failing grade always stays 5.0.
+func (g gradeSet) Map(grade float64) float64 {
+	if len(g.allowed) == 0 {
+		return grade
+	}
+	if grade > 4.0+1e-9 {
+		return failedGrade
+	}
+
+	better, worse := math.NaN(), math.NaN()
+	for _, allowed := range g.allowed {
+		switch {
+		case sameGrade(allowed, grade):
+			return allowed
+		case allowed < grade:
+			better = allowed
+		case allowed <= 4.0+1e-9 && math.IsNaN(worse):
+			worse = allowed
+		}
+	}
+	if math.IsNaN(better) {
+		return worse
+	}
+	if math.IsNaN(worse) {
+		return better
+	}
+
+	switch g.mapping {
+	case MapWorse:
+		return worse
+	case MapNearest:
+		if worse-grade < grade-better-1e-9 {
+			return worse
+		}
+	}
+	return better
+}
+
+func sameGrade(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
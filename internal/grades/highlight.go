@@ -0,0 +1,134 @@
+package grades
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	HighlightFailed      = "failed"
+	HighlightTop         = "top"
+	HighlightAboveMax    = "above-max"
+	HighlightDuplicate   = "duplicate"
+	HighlightMissingSeat = "missing-seat"
+	HighlightNone        = "none"
+)
+
+// Highlight levels in increasing priority; a row shows the highest level of
+// all rules it matches.
+const (
+	LevelNone    = ""
+	LevelTop     = "top"
+	LevelFailed  = "failed"
+	LevelWarning = "warning"
+)
+
+const DefaultTopGrade = 1.3
+
+var DefaultHighlights = []string{HighlightFailed, HighlightTop, HighlightAboveMax, HighlightDuplicate, HighlightMissingSeat}
+
+// ParseHighlights splits a comma separated list of highlight rules.
+func ParseHighlights(text string) []string {
+	rules := make([]string, 0)
+	for _, rule := range strings.Split(text, ",") {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+type highlighter struct {
+	rules    map[string]bool
+	topGrade float64
+}
+
+// NewHighlighter enables the given rules; "none" alone disables all of them.
+func NewHighlighter(rules []string, topGrade float64) (highlighter, error) {
+	h := highlighter{rules: make(map[string]bool), topGrade: topGrade}
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		switch rule {
+		case HighlightFailed, HighlightTop, HighlightAboveMax, HighlightDuplicate, HighlightMissingSeat:
+			h.rules[rule] = true
+		case "", HighlightNone:
+		default:
+			return highlighter{}, fmt.Errorf("unknown highlight rule %q (use %s or none)", rule, strings.Join(DefaultHighlights, ", "))
+		}
+	}
+	if topGrade < 0.7 || topGrade > 4.0 {
+		return highlighter{}, fmt.Errorf("top grade %.1f out of range (0.7 to 4.0)", topGrade)
+	}
+	return h, nil
+}
+
+func (h highlighter) Enabled(rule string) bool {
+	return h.rules[rule]
+}
+
+func (h highlighter) TopGrade() float64 {
+	return h.topGrade
+}
+
+type highlight struct {
+	level   string
+	reasons []string
+}
+
+func (h highlight) Level() string {
+	return h.level
+}
+
+// Tooltip joins the reasons of all matching rules.
+func (h highlight) Tooltip() string {
+	return strings.Join(h.reasons, "; ")
+}
+
+func (h *highlight) add(level, reason string) {
+	if levelPriority(level) > levelPriority(h.level) {
+		h.level = level
+	}
+	h.reasons = append(h.reasons, reason)
+}
+
+func levelPriority(level string) int {
+	switch level {
+	case LevelTop:
+		return 1
+	case LevelFailed:
+		return 2
+	case LevelWarning:
+		return 3
+	}
+	return 0
+}
+
+// Highlights returns one highlight per student in the order of
+// GradedStudentTable.
+func (e exam) Highlights(h highlighter) []highlight {
+	matNrs := make(map[string]int)
+	for _, s := range e.students {
+		matNrs[s.matNr]++
+	}
+
+	result := make([]highlight, len(e.students))
+	for i, s := range e.students {
+		grade := e.Grade(s)
+		if h.rules[HighlightFailed] && grade > 4.0 {
+			result[i].add(LevelFailed, "failed")
+		}
+		if h.rules[HighlightTop] && grade <= h.topGrade+1e-9 {
+			result[i].add(LevelTop, fmt.Sprintf("top grade %.1f", grade))
+		}
+		if h.rules[HighlightAboveMax] && e.Points(s) > e.pMax {
+			result[i].add(LevelWarning, fmt.Sprintf("points %.1f above max points %.1f", e.Points(s), e.pMax))
+		}
+		if h.rules[HighlightDuplicate] && matNrs[s.matNr] > 1 {
+			result[i].add(LevelWarning, fmt.Sprintf("duplicate matriculation number %s", s.matNr))
+		}
+		if h.rules[HighlightMissingSeat] && strings.TrimSpace(s.seatNr) == "" {
+			result[i].add(LevelWarning, "missing seat")
+		}
+	}
+	return result
+}
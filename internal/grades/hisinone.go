@@ -0,0 +1,82 @@
+package grades
+
+import (
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+// HISinOne/FlexNow expect grades as integers (1.3 -> 130) and a status code
+// per student. The import is a semicolon separated file with a fixed header
+// block in front of the grade table.
+const (
+	hisInOneDelimiter  = ';'
+	hisInOneStatusPass = "BE"
+	hisInOneStatusFail = "NB"
+)
+
+type hisInOneExport struct {
+	examNr   string
+	semester string
+}
+
+func NewHISinOneExport(examNr, semester string) hisInOneExport {
+	return hisInOneExport{
+		examNr:   examNr,
+		semester: semester,
+	}
+}
+
+func (h hisInOneExport) ExamNr() string {
+	return h.examNr
+}
+
+func (h hisInOneExport) Semester() string {
+	return h.semester
+}
+
+func (h hisInOneExport) HeaderBlock(e exam) [][]string {
+	return [][]string{
+		{"[Pruefung]"},
+		{"Pruefungsnummer", h.examNr},
+		{"Semester", h.semester},
+		{"Anzahl", strconv.Itoa(e.AmountStudents())},
+		{},
+	}
+}
+
+func (h hisInOneExport) Table(e exam) *utilities.Table {
+	header := []string{"mtknr", "pnr", "semester", "bewertung", "pstatus"}
+	rows := make([]utilities.TableRow, 0)
+	for _, s := range e.students {
+		grade := e.Grade(s)
+		row := utilities.TableRow{s.matNr, h.examNr, h.semester, hisInOneGrade(grade), hisInOneStatus(grade)}
+		rows = append(rows, row)
+	}
+	return utilities.NewTable(header, rows)
+}
+
+func (h hisInOneExport) ToCSV(e exam, filepath string) error {
+	return utilities.WriteCSVWithPreamble(filepath, h.HeaderBlock(e), hisInOneDelimiter, *h.Table(e))
+}
+
+// ToXLSX writes the same header block and grade table to the first sheet of
+// an Excel workbook, for uploads that expect XLSX instead of CSV.
+func (h hisInOneExport) ToXLSX(e exam, filepath string) error {
+	return utilities.WriteFileAtomic(filepath, func(w io.Writer) error {
+		return utilities.WriteXLSXTo(w, "Pruefung", h.HeaderBlock(e), *h.Table(e))
+	})
+}
+
+func hisInOneGrade(grade float64) int {
+	return int(math.Round(grade * 100))
+}
+
+func hisInOneStatus(grade float64) string {
+	if grade > 4.0 {
+		return hisInOneStatusFail
+	}
+	return hisInOneStatusPass
+}
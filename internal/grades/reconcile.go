@@ -0,0 +1,152 @@
+package grades
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+const (
+	RuleAverage = "average"
+	RuleMin     = "min"
+	RuleMax     = "max"
+	RuleFirst   = "first"
+)
+
+type reconciler struct {
+	rule      string
+	threshold float64
+}
+
+func NewReconciler(rule string, threshold float64) (reconciler, error) {
+	switch rule {
+	case RuleAverage, RuleMin, RuleMax, RuleFirst:
+	default:
+		return reconciler{}, fmt.Errorf("unknown reconciliation rule %q (use %s, %s, %s or %s)", rule, RuleAverage, RuleMin, RuleMax, RuleFirst)
+	}
+	if threshold <= 0 {
+		return reconciler{}, fmt.Errorf("grade difference threshold must be > 0")
+	}
+	return reconciler{rule: rule, threshold: threshold}, nil
+}
+
+type reconciliation struct {
+	first        student
+	second       student
+	firstGrade   float64
+	secondGrade  float64
+	mergedPoints float64
+	flagged      bool
+}
+
+func (r reconciliation) GradeDifference() float64 {
+	return math.Abs(r.firstGrade - r.secondGrade)
+}
+
+func (r reconciliation) Flagged() bool {
+	return r.flagged
+}
+
+type reconciliations []reconciliation
+
+// Reconcile merges the points of two independent examiners. Both exams must
+// contain the same students, each only once; the result is a regular exam graded with the
+// settings of the first one.
+func (r reconciler) Reconcile(first, second exam) (exam, reconciliations, error) {
+	merged := NewExam(first.pMax, first.pPass)
+	merged.SetBonus(first.bonus)
+	merged.SetRounding(first.rounding)
+	merged.SetGradeSet(first.gradeSet)
+	second.SetBonus(first.bonus)
+	second.SetRounding(first.rounding)
+	second.SetGradeSet(first.gradeSet)
+	result := make(reconciliations, 0)
+
+	if matNr, ok := first.duplicateMatNr(); ok {
+		return merged, result, fmt.Errorf("duplicate matriculation number %q in the first examiner's points", matNr)
+	}
+	if matNr, ok := second.duplicateMatNr(); ok {
+		return merged, result, fmt.Errorf("duplicate matriculation number %q in the second examiner's points", matNr)
+	}
+	for _, s := range second.students {
+		if first.findStudent(s.matNr) < 0 {
+			return merged, result, fmt.Errorf("student %q is missing in the first examiner's points", s.matNr)
+		}
+	}
+	for _, s1 := range first.students {
+		idx := second.findStudent(s1.matNr)
+		if idx < 0 {
+			return merged, result, fmt.Errorf("student %q is missing in the second examiner's points", s1.matNr)
+		}
+		s2 := second.students[idx]
+
+		rec := reconciliation{
+			first:        s1,
+			second:       s2,
+			firstGrade:   first.Grade(s1),
+			secondGrade:  second.Grade(s2),
+			mergedPoints: r.combine(s1.points, s2.points),
+		}
+		rec.flagged = rec.GradeDifference() > r.threshold+1e-9
+		result = append(result, rec)
+
+		merged.AddStudent(NewStudent(s1.name, s1.matNr, s1.seatNr, rec.mergedPoints, s1.comment))
+	}
+	return merged, result, nil
+}
+
+func (r reconciler) combine(first, second float64) float64 {
+	switch r.rule {
+	case RuleMin:
+		return math.Min(first, second)
+	case RuleMax:
+		return math.Max(first, second)
+	case RuleFirst:
+		return first
+	}
+	return (first + second) / 2
+}
+
+func (r reconciliations) Flagged() reconciliations {
+	flagged := make(reconciliations, 0)
+	for _, rec := range r {
+		if rec.flagged {
+			flagged = append(flagged, rec)
+		}
+	}
+	return flagged
+}
+
+func (r reconciliations) Table() *utilities.Table {
+	header := []string{"Student Name", "Mat", "Points 1", "Points 2", "Grade 1", "Grade 2", "Difference", "Merged Points", "Third Examiner"}
+	rows := make([]utilities.TableRow, 0)
+	for _, rec := range r {
+		third := ""
+		if rec.flagged {
+			third = "required"
+		}
+		rows = append(rows, utilities.TableRow{
+			rec.first.name, rec.first.matNr, rec.first.points, rec.second.points,
+			rec.firstGrade, rec.secondGrade, rec.GradeDifference(), rec.mergedPoints, third,
+		})
+	}
+	hooks := map[int]utilities.FormatHook{
+		2: utilities.BuildDecimalFormatHook(1),
+		3: utilities.BuildDecimalFormatHook(1),
+		4: utilities.BuildDecimalFormatHook(1),
+		5: utilities.BuildDecimalFormatHook(1),
+		6: utilities.BuildDecimalFormatHook(1),
+		7: utilities.BuildDecimalFormatHook(2),
+	}
+	table := utilities.NewTable(header, rows)
+	table.SetFormatHooks(hooks)
+	return table
+}
+
+func (r reconciliations) String() string {
+	return fmt.Sprintf(
+		"Reconciliation of %d students (%d need a third examiner):\n%s", len(r), len(r.Flagged()),
+		r.Table().FormatTableRight([]int{2, 3, 4, 5, 6, 7}),
+	)
+}
@@ -0,0 +1,93 @@
+package grades
+
+import (
+	"strings"
+	"testing"
+)
+
+func reconcileExams(t *testing.T, points []float64) (exam, exam) {
+	t.Helper()
+	first := NewExam(90, 45)
+	second := NewExam(90, 45)
+	for i, p := range points {
+		matNr := string(rune('1' + i))
+		first.AddStudent(NewStudent("Student "+matNr, matNr, "", p, ""))
+		second.AddStudent(NewStudent("Student "+matNr, matNr, "", p, ""))
+	}
+	return first, second
+}
+
+// TestReconcileSameRounding grades identical points of both examiners with
+// the rounding of the first exam, so no grade may differ.
+func TestReconcileSameRounding(t *testing.T) {
+	first, second := reconcileExams(t, []float64{45, 60, 75, 83, 90})
+	r, err := NewRounding(StepWhole, RoundNearest, DefaultGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.SetRounding(r)
+
+	reconciler, err := NewReconciler(RuleAverage, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, result, err := reconciler.Reconcile(first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range result {
+		if rec.GradeDifference() != 0 {
+			t.Errorf("student %s: grades %.1f and %.1f differ for identical points", rec.first.matNr, rec.firstGrade, rec.secondGrade)
+		}
+	}
+	if flagged := result.Flagged(); len(flagged) > 0 {
+		t.Errorf("%d students flagged for identical points", len(flagged))
+	}
+}
+
+// TestReconcileSameGradeSet maps the grades of both examiners into the
+// allowed grades of the first exam.
+func TestReconcileSameGradeSet(t *testing.T) {
+	first, second := reconcileExams(t, []float64{45, 47, 52, 60, 70, 80, 90})
+	g, err := NewGradeSet([]float64{1.0, 2.0, 3.0, 4.0}, MapWorse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.SetGradeSet(g)
+
+	reconciler, err := NewReconciler(RuleAverage, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, result, err := reconciler.Reconcile(first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range result {
+		if rec.GradeDifference() != 0 {
+			t.Errorf("student %s: grades %.1f and %.1f differ for identical points", rec.first.matNr, rec.firstGrade, rec.secondGrade)
+		}
+	}
+}
+
+// TestReconcileDuplicateMatNr refuses duplicates in either exam instead of
+// merging only the first of them.
+func TestReconcileDuplicateMatNr(t *testing.T) {
+	reconciler, err := NewReconciler(RuleAverage, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dup := range []string{"first", "second"} {
+		first, second := reconcileExams(t, []float64{45, 60})
+		duplicate := NewStudent("Student 2", "2", "", 70, "")
+		if dup == "first" {
+			first.AddStudent(duplicate)
+		} else {
+			second.AddStudent(duplicate)
+		}
+		_, _, err := reconciler.Reconcile(first, second)
+		if err == nil || !strings.Contains(err.Error(), `"2"`) || !strings.Contains(err.Error(), dup) {
+			t.Errorf("duplicate in the %s exam: got error %v", dup, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package grades
+
+import (
+	"io"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+// ReportStyle colours the rows by highlight level like the GUI does.
+const ReportStyle = `body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #bbb; padding: 0.25em 0.6em; }
+th { background: #eee; }
+tr.failed { background: #f8d0d0; }
+tr.top { background: #d3f0d3; }
+tr.warning { background: #fbe6b8; }
+tr[title] { cursor: help; }`
+
+// WriteHTML writes the graded students and the grading key as an HTML page.
+// Rows are coloured and get tooltips by the rules of the highlighter.
+func (e exam) WriteHTML(w io.Writer, title string, h highlighter) error {
+	highlights := e.Highlights(h)
+	classes := make([]string, len(highlights))
+	titles := make([]string, len(highlights))
+	for i, hl := range highlights {
+		classes[i] = hl.Level()
+		titles[i] = hl.Tooltip()
+	}
+
+	sections := []utilities.HTMLSection{
+		{Title: "Graded Students", Table: *e.GradedStudentTable(), RowClasses: classes, RowTitles: titles},
+		{Title: "Grading Key", Table: *e.GradingKeyTable()},
+	}
+	return utilities.WriteHTMLTo(w, title, ReportStyle, sections)
+}
+
+func (e exam) ToHTML(path, title string, h highlighter) error {
+	return utilities.WriteFileAtomic(path, func(w io.Writer) error {
+		return e.WriteHTML(w, title, h)
+	})
+}
+
+// WritePDF lays out the grading key and the graded students on A4 pages for
+// printing.
+func (e exam) WritePDF(w io.Writer, page utilities.PrintPage) error {
+	sections := []utilities.PrintSection{
+		{Title: "Grading Key", Table: *e.GradingKeyTable()},
+		{Title: "Graded Students", Table: *e.GradedStudentTable()},
+	}
+	return utilities.WritePDFTo(w, page, sections)
+}
+
+func (e exam) ToPDF(path string, page utilities.PrintPage) error {
+	return utilities.WriteFileAtomic(path, func(w io.Writer) error {
+		return e.WritePDF(w, page)
+	})
+}
@@ -0,0 +1,149 @@
+package grades
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+const (
+	PolicyBest       = "best"
+	PolicyLatest     = "latest"
+	PolicyFailedOnly = "failed-only"
+)
+
+type retakeMerger struct {
+	policy string
+}
+
+func NewRetakeMerger(policy string) (retakeMerger, error) {
+	switch policy {
+	case PolicyBest, PolicyLatest, PolicyFailedOnly:
+	default:
+		return retakeMerger{}, fmt.Errorf("unknown retake policy %q (use %s, %s or %s)", policy, PolicyBest, PolicyLatest, PolicyFailedOnly)
+	}
+	return retakeMerger{policy: policy}, nil
+}
+
+type attempt struct {
+	taken bool
+	grade float64
+}
+
+type retakeResult struct {
+	name    string
+	matNr   string
+	first   attempt
+	retake  attempt
+	final   float64
+	counted string
+	note    string
+}
+
+type retakeResults []retakeResult
+
+// Merge links the first exam and the retake by matriculation number. Each
+// exam is graded with its own settings; students who took only one of the
+// attempts keep that grade. A matriculation number may occur only once per
+// exam, otherwise it is unclear which attempt to link.
+func (m retakeMerger) Merge(first, retake exam) (retakeResults, error) {
+	if matNr, ok := first.duplicateMatNr(); ok {
+		return nil, fmt.Errorf("duplicate matriculation number %q in the first exam", matNr)
+	}
+	if matNr, ok := retake.duplicateMatNr(); ok {
+		return nil, fmt.Errorf("duplicate matriculation number %q in the retake", matNr)
+	}
+	results := make(map[string]*retakeResult)
+	for _, s := range first.students {
+		results[s.matNr] = &retakeResult{name: s.name, matNr: s.matNr, first: attempt{taken: true, grade: first.Grade(s)}}
+	}
+	for _, s := range retake.students {
+		r, ok := results[s.matNr]
+		if !ok {
+			r = &retakeResult{name: s.name, matNr: s.matNr}
+			results[s.matNr] = r
+		}
+		r.retake = attempt{taken: true, grade: retake.Grade(s)}
+	}
+
+	merged := make(retakeResults, 0, len(results))
+	for _, r := range results {
+		m.decide(r)
+		merged = append(merged, *r)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].matNr < merged[j].matNr
+	})
+	return merged, nil
+}
+
+func (m retakeMerger) decide(r *retakeResult) {
+	switch {
+	case !r.retake.taken:
+		r.final, r.counted = r.first.grade, "first"
+		return
+	case !r.first.taken:
+		r.final, r.counted = r.retake.grade, "retake"
+		return
+	}
+
+	switch m.policy {
+	case PolicyBest:
+		if r.retake.grade < r.first.grade {
+			r.final, r.counted = r.retake.grade, "retake"
+		} else {
+			r.final, r.counted = r.first.grade, "first"
+		}
+	case PolicyLatest:
+		r.final, r.counted = r.retake.grade, "retake"
+	case PolicyFailedOnly:
+		if r.first.grade > 4.0 {
+			r.final, r.counted = r.retake.grade, "retake"
+		} else {
+			r.final, r.counted = r.first.grade, "first"
+			r.note = "retake not allowed, first attempt passed"
+		}
+	}
+}
+
+// NotAllowed returns the students whose retake was ignored by the policy.
+func (r retakeResults) NotAllowed() retakeResults {
+	ignored := make(retakeResults, 0)
+	for _, res := range r {
+		if res.note != "" {
+			ignored = append(ignored, res)
+		}
+	}
+	return ignored
+}
+
+func (r retakeResults) Table() *utilities.Table {
+	header := []string{"Student Name", "Mat", "First Grade", "Retake Grade", "Final Grade", "Counted", "Note"}
+	rows := make([]utilities.TableRow, 0)
+	for _, res := range r {
+		var firstGrade, retakeGrade any = "", ""
+		if res.first.taken {
+			firstGrade = res.first.grade
+		}
+		if res.retake.taken {
+			retakeGrade = res.retake.grade
+		}
+		rows = append(rows, utilities.TableRow{res.name, res.matNr, firstGrade, retakeGrade, res.final, res.counted, res.note})
+	}
+	hooks := map[int]utilities.FormatHook{
+		2: utilities.BuildDecimalFormatHook(1),
+		3: utilities.BuildDecimalFormatHook(1),
+		4: utilities.BuildDecimalFormatHook(1),
+	}
+	table := utilities.NewTable(header, rows)
+	table.SetFormatHooks(hooks)
+	return table
+}
+
+func (r retakeResults) String() string {
+	return fmt.Sprintf(
+		"Merged attempts of %d students:\n%s", len(r),
+		r.Table().FormatTableRight([]int{2, 3, 4}),
+	)
+}
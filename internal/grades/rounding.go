@@ -0,0 +1,93 @@
+package grades
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	StepThirds = "thirds"
+	StepTenths = "tenths"
+	StepHalves = "halves"
+	StepWhole  = "whole"
+)
+
+const (
+	RoundNearest   = "nearest"
+	RoundFavorable = "favorable"
+)
+
+const (
+	DefaultStep        = StepThirds
+	DefaultRounding    = RoundNearest
+	DefaultGranularity = 0.5
+)
+
+// rounding describes how the raw linear grade is mapped onto the grade steps
+// and in which point increments the grading key is listed.
+type rounding struct {
+	step        string
+	direction   string
+	granularity float64
+}
+
+func NewRounding(step, direction string, granularity float64) (rounding, error) {
+	switch step {
+	case StepThirds, StepTenths, StepHalves, StepWhole:
+	default:
+		return rounding{}, fmt.Errorf("unknown grade step %q (use %s, %s, %s or %s)", step, StepThirds, StepTenths, StepHalves, StepWhole)
+	}
+	switch direction {
+	case RoundNearest, RoundFavorable:
+	default:
+		return rounding{}, fmt.Errorf("unknown rounding %q (use %s or %s)", direction, RoundNearest, RoundFavorable)
+	}
+	switch granularity {
+	case 0.25, 0.5, 1:
+	default:
+		return rounding{}, fmt.Errorf("unsupported key granularity %v (use 0.25, 0.5 or 1)", granularity)
+	}
+	return rounding{step: step, direction: direction, granularity: granularity}, nil
+}
+
+func defaultRounding() rounding {
+	return rounding{step: DefaultStep, direction: DefaultRounding, granularity: DefaultGranularity}
+}
+
+func (r rounding) Step() string {
+	return r.step
+}
+
+func (r rounding) Direction() string {
+	return r.direction
+}
+
+func (r rounding) Granularity() float64 {
+	return r.granularity
+}
+
+// stepsPerGrade is the number of grade steps between two whole grades.
+func (r rounding) stepsPerGrade() float64 {
+	switch r.step {
+	case StepTenths:
+		return 10
+	case StepHalves:
+		return 2
+	case StepWhole:
+		return 1
+	}
+	return 3
+}
+
+// steps rounds a raw grade offset to a whole number of grade steps. Rounding
+// in favor of the student always picks the better, i.e. lower, grade.
+func (r rounding) steps(offset float64) float64 {
+	if r.direction == RoundFavorable {
+		return math.Floor(r.stepsPerGrade()*offset + 1e-9)
+	}
+	return math.Floor(r.stepsPerGrade()*offset + 0.5)
+}
+
+func (r rounding) String() string {
+	return fmt.Sprintf("%s, %s, key every %v points", r.step, r.direction, r.granularity)
+}
@@ -0,0 +1,158 @@
+package grades
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+type statistics struct {
+	amount       int
+	passed       int
+	failed       int
+	meanPoints   float64
+	medianPoints float64
+	minPoints    float64
+	maxPoints    float64
+	meanGrade    float64
+}
+
+func (e exam) Statistics() statistics {
+	stats := statistics{amount: len(e.students)}
+	if stats.amount == 0 {
+		return stats
+	}
+
+	points := make([]float64, 0, len(e.students))
+	sumPoints, sumGrades := 0.0, 0.0
+	for _, s := range e.students {
+		grade := e.Grade(s)
+		if e.Passed(s) {
+			stats.passed++
+		} else {
+			stats.failed++
+		}
+		points = append(points, e.Points(s))
+		sumPoints += e.Points(s)
+		sumGrades += grade
+	}
+	sort.Float64s(points)
+
+	stats.meanPoints = sumPoints / float64(stats.amount)
+	stats.meanGrade = sumGrades / float64(stats.amount)
+	stats.minPoints = points[0]
+	stats.maxPoints = points[len(points)-1]
+	stats.medianPoints = median(points)
+	return stats
+}
+
+func (s statistics) Amount() int {
+	return s.amount
+}
+
+func (s statistics) Passed() int {
+	return s.passed
+}
+
+func (s statistics) Failed() int {
+	return s.failed
+}
+
+func (s statistics) PassRate() float64 {
+	if s.amount == 0 {
+		return 0
+	}
+	return 100 * float64(s.passed) / float64(s.amount)
+}
+
+func (s statistics) MeanPoints() float64 {
+	return s.meanPoints
+}
+
+func (s statistics) MedianPoints() float64 {
+	return s.medianPoints
+}
+
+func (s statistics) MinPoints() float64 {
+	return s.minPoints
+}
+
+func (s statistics) MaxPoints() float64 {
+	return s.maxPoints
+}
+
+func (s statistics) MeanGrade() float64 {
+	return s.meanGrade
+}
+
+func (e exam) Passed(s student) bool {
+	return e.Grade(s) <= 4.0
+}
+
+func (e exam) StatisticsTable() *utilities.Table {
+	stats := e.Statistics()
+	header := []string{"Metric", "Value"}
+	rows := []utilities.TableRow{
+		{"Students", stats.amount},
+		{"Passed", stats.passed},
+		{"Failed", stats.failed},
+		{"Pass rate", fmt.Sprintf("%.1f%%", stats.PassRate())},
+		{"Mean points", fmt.Sprintf("%.1f", stats.meanPoints)},
+		{"Median points", fmt.Sprintf("%.1f", stats.medianPoints)},
+		{"Min points", fmt.Sprintf("%.1f", stats.minPoints)},
+		{"Max points", fmt.Sprintf("%.1f", stats.maxPoints)},
+		{"Mean grade", fmt.Sprintf("%.2f", stats.meanGrade)},
+	}
+	return utilities.NewTable(header, rows)
+}
+
+func (e exam) GradeDistribution() map[float64]int {
+	distribution := make(map[float64]int)
+	for _, s := range e.students {
+		distribution[e.Grade(s)]++
+	}
+	return distribution
+}
+
+func (e exam) GradeDistributionTable() *utilities.Table {
+	distribution := e.GradeDistribution()
+	grades := make([]float64, 0, len(distribution))
+	for grade := range distribution {
+		grades = append(grades, grade)
+	}
+	sort.Float64s(grades)
+
+	header := []string{"Grade", "Students", "%"}
+	rows := make([]utilities.TableRow, 0)
+	for _, grade := range grades {
+		count := distribution[grade]
+		share := 100 * float64(count) / float64(e.AmountStudents())
+		rows = append(rows, utilities.TableRow{grade, count, fmt.Sprintf("%.1f%%", share)})
+	}
+	hooks := map[int]utilities.FormatHook{
+		0: utilities.BuildDecimalFormatHook(1),
+	}
+	table := utilities.NewTable(header, rows)
+	table.SetFormatHooks(hooks)
+	return table
+}
+
+func (e exam) StatisticsString() string {
+	return fmt.Sprintf(
+		"Statistics for %d students:\n%s\nGrade distribution:\n%s", e.AmountStudents(),
+		e.StatisticsTable().FormatTableRight([]int{1}),
+		e.GradeDistributionTable().FormatTableRight([]int{0, 1, 2}),
+	)
+}
+
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,197 @@
+package grades
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+const (
+	DefaultMatNrPattern = `^[0-9]+$`
+	maxStudentColumns   = 5
+)
+
+type validationIssue struct {
+	line    int
+	matNr   string
+	message string
+}
+
+func (i validationIssue) Line() int {
+	return i.line
+}
+
+func (i validationIssue) Message() string {
+	return i.message
+}
+
+func (i validationIssue) String() string {
+	if i.line == 0 {
+		return i.message
+	}
+	return fmt.Sprintf("line %d: %s", i.line, i.message)
+}
+
+type validationIssues []validationIssue
+
+func (v validationIssues) Table() *utilities.Table {
+	header := []string{"Line", "Mat", "Problem"}
+	rows := make([]utilities.TableRow, 0)
+	for _, issue := range v {
+		rows = append(rows, utilities.TableRow{issue.line, issue.matNr, issue.message})
+	}
+	return utilities.NewTable(header, rows)
+}
+
+func (v validationIssues) String() string {
+	return fmt.Sprintf("%d problem(s) found:\n%s", len(v), v.Table().FormatTableRight([]int{0}))
+}
+
+type validator struct {
+	pMax         float64
+	matNrPattern *regexp.Regexp
+
+	issues     validationIssues
+	matNrLines map[string]int
+	seatLines  map[string]int
+	separator  string
+	sepLine    int
+}
+
+func NewValidator(pMax float64, matNrPattern string) (*validator, error) {
+	if strings.TrimSpace(matNrPattern) == "" {
+		matNrPattern = DefaultMatNrPattern
+	}
+	pattern, err := regexp.Compile(matNrPattern)
+	if err != nil {
+		return nil, fmt.Errorf("compile matriculation number pattern: %w", err)
+	}
+	return &validator{
+		pMax:         pMax,
+		matNrPattern: pattern,
+	}, nil
+}
+
+func (v *validator) ValidateCSV(filepath string) (validationIssues, error) {
+	lines, err := utilities.ReadCSVLines(filepath)
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		issues := v.ValidateLines(lines)
+		return append(issues, validationIssue{line: parseErr.Line, message: parseErr.Err.Error()}), nil
+	}
+	if err != nil {
+		return validationIssues{}, err
+	}
+	return v.ValidateLines(lines), nil
+}
+
+func (v *validator) ValidateLines(lines []utilities.CSVLine) validationIssues {
+	v.issues = make(validationIssues, 0)
+	v.matNrLines = make(map[string]int)
+	v.seatLines = make(map[string]int)
+	v.separator = ""
+	v.sepLine = 0
+
+	if len(lines) == 0 {
+		v.add(0, "", "file is empty")
+		return v.issues
+	}
+	header := lines[0]
+	if len(header.Fields()) < 4 {
+		v.add(header.Number(), "", fmt.Sprintf("header: expected at least 4 columns (name, matNr, seatNr, points), got %d", len(header.Fields())))
+	}
+	for _, line := range lines[1:] {
+		v.validateLine(line)
+	}
+	return v.issues
+}
+
+func (v *validator) validateLine(line utilities.CSVLine) {
+	fields := line.Fields()
+	if len(fields) < 4 {
+		v.add(line.Number(), "", fmt.Sprintf("expected at least 4 columns (name, matNr, seatNr, points), got %d", len(fields)))
+		return
+	}
+	if len(fields) > maxStudentColumns {
+		v.add(line.Number(), strings.TrimSpace(fields[1]), fmt.Sprintf("too many columns: expected at most %d, got %d (unquoted comma in comment?)", maxStudentColumns, len(fields)))
+	}
+
+	name := strings.TrimSpace(fields[0])
+	matNr := strings.TrimSpace(fields[1])
+	seatNr := strings.TrimSpace(fields[2])
+	points := strings.TrimSpace(fields[3])
+
+	if name == "" {
+		v.add(line.Number(), matNr, "empty name")
+	}
+	v.validateMatNr(line.Number(), matNr)
+	v.validateSeat(line.Number(), matNr, seatNr)
+	v.validatePoints(line.Number(), matNr, points)
+}
+
+func (v *validator) validateMatNr(line int, matNr string) {
+	if !v.matNrPattern.MatchString(matNr) {
+		v.add(line, matNr, fmt.Sprintf("malformed matriculation number %q (expected %s)", matNr, v.matNrPattern))
+	}
+	if matNr == "" {
+		return
+	}
+	if first, ok := v.matNrLines[matNr]; ok {
+		v.add(line, matNr, fmt.Sprintf("duplicate matriculation number (first seen in line %d)", first))
+		return
+	}
+	v.matNrLines[matNr] = line
+}
+
+func (v *validator) validateSeat(line int, matNr, seatNr string) {
+	if seatNr == "" {
+		return
+	}
+	if first, ok := v.seatLines[seatNr]; ok {
+		v.add(line, matNr, fmt.Sprintf("duplicate seat %q (first seen in line %d)", seatNr, first))
+		return
+	}
+	v.seatLines[seatNr] = line
+}
+
+func (v *validator) validatePoints(line int, matNr, points string) {
+	separator := ""
+	switch {
+	case strings.Contains(points, ","):
+		separator = ","
+	case strings.Contains(points, "."):
+		separator = "."
+	}
+	if separator != "" {
+		if v.separator == "" {
+			v.separator = separator
+			v.sepLine = line
+		} else if v.separator != separator {
+			v.add(line, matNr, fmt.Sprintf("mixed decimal separators: %q here, %q in line %d", separator, v.separator, v.sepLine))
+		}
+	}
+
+	value, err := strconv.ParseFloat(strings.Replace(points, ",", ".", 1), 64)
+	if err != nil {
+		v.add(line, matNr, fmt.Sprintf("invalid points %q", points))
+		return
+	}
+	if separator == "," {
+		v.add(line, matNr, fmt.Sprintf("decimal comma in points %q (use %q)", points, strings.Replace(points, ",", ".", 1)))
+	}
+	if value < 0 {
+		v.add(line, matNr, fmt.Sprintf("points %.2f below 0", value))
+	}
+	if value > v.pMax {
+		v.add(line, matNr, fmt.Sprintf("points %.2f above max points %.2f", value, v.pMax))
+	}
+}
+
+func (v *validator) add(line int, matNr, message string) {
+	v.issues = append(v.issues, validationIssue{line: line, matNr: matNr, message: message})
+}
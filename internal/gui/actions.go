@@ -1,15 +1,20 @@
 package gui
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/storage"
 	"github.com/andreaswillibaldweber/gogrades/internal/grades"
+	"github.com/andreaswillibaldweber/gogrades/internal/project"
 	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
 )
 
@@ -17,37 +22,53 @@ func (g *GUI) renderTables() {
 	if g.gradedStudents == nil || g.gradingKey == nil {
 		g.gradedTable.clear()
 		g.keyTable.clear()
+		g.renderSidePanel(0)
+		g.renderCharts()
 		return
 	}
 
 	g.gradedTable.setData(g.gradedStudents, formatGradedCell)
-	g.keyTable.setData(g.gradingKey, formatGradingKeyCell)
+	g.keyTable.setData(g.gradingKey, g.gradingKey.FormatCell)
 
-	leftWidth, rightWidth := g.tablePaneWidths()
+	leftWidth, rightWidth, sideWidth := g.tablePaneWidths()
 	g.gradedTable.resizeToFit(leftWidth)
 	g.keyTable.resizeToFit(rightWidth)
 	g.gradedTable.Widget().Refresh()
 	g.keyTable.Widget().Refresh()
+	g.renderSidePanel(sideWidth)
+	g.renderCharts()
 }
 
-func (g *GUI) tablePaneWidths() (float32, float32) {
+func (g *GUI) tablePaneWidths() (float32, float32, float32) {
 	canvasWidth := g.window.Canvas().Size().Width
 	if canvasWidth <= 0 {
 		canvasWidth = windowWidth
 	}
-	left := canvasWidth*float32(splitOffset) - 24
-	right := canvasWidth*(1-float32(splitOffset)) - 24
+	tablesWidth := canvasWidth * float32(g.sideSplit.Offset)
+	left := tablesWidth*float32(g.tablesSplit.Offset) - 24
+	right := tablesWidth*(1-float32(g.tablesSplit.Offset)) - 24
+	side := canvasWidth*(1-float32(g.sideSplit.Offset)) - 24
 	if left < 240 {
 		left = 240
 	}
 	if right < 240 {
 		right = 240
 	}
-	return left, right
+	if side < 180 {
+		side = 180
+	}
+	return left, right, side
 }
 
 func (g *GUI) rebuildTables() error {
 	exam := grades.NewExam(g.pMax, g.pPass)
+	if err := exam.Configure(g.project); err != nil {
+		return err
+	}
+	highlighter, err := grades.NewHighlighter(g.project.Highlights(), g.project.TopGrade())
+	if err != nil {
+		return err
+	}
 	if g.loadedTable != nil {
 		students, err := grades.NewStudentsFromTable(g.loadedTable)
 		if err != nil {
@@ -55,8 +76,55 @@ func (g *GUI) rebuildTables() error {
 		}
 		exam.AddStudents(students)
 	}
+	if g.bonusTable != nil {
+		bonus, err := grades.NewBonusFromTable(g.bonusTable)
+		if err != nil {
+			return err
+		}
+		exam.SetBonus(bonus)
+	}
+	if g.correctionsTable != nil {
+		corrections, err := grades.NewCorrectionsFromTable(g.correctionsTable)
+		if err != nil {
+			return err
+		}
+		if err := exam.ApplyCorrections(corrections); err != nil {
+			return err
+		}
+	}
 	g.gradedStudents = exam.GradedStudentTable()
 	g.gradingKey = exam.GradingKeyTable()
+	g.gradeFor = func(matNr string, points float64) float64 {
+		return exam.Grade(*grades.NewStudent("", matNr, "", points, ""))
+	}
+	g.writeHTMLReport = func(w io.Writer) error {
+		return exam.WriteHTML(w, g.project.ReportHeading(), highlighter)
+	}
+	g.writePDFReport = func(w io.Writer, signature bool) error {
+		return exam.WritePDF(w, g.project.PrintPage(signature))
+	}
+	g.logCorrection = func(matNr string, points float64, reason, examiner string) error {
+		correction, err := exam.Correct(matNr, points, reason, examiner, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		return grades.AppendCorrectionCSV(g.project.CorrectionsFile(), correction)
+	}
+	g.rowLevels = nil
+	g.rowTooltips = nil
+	for _, hl := range exam.Highlights(highlighter) {
+		g.rowLevels = append(g.rowLevels, hl.Level())
+		g.rowTooltips = append(g.rowTooltips, hl.Tooltip())
+	}
+	g.statistics = nil
+	g.distribution = nil
+	if exam.AmountStudents() > 0 {
+		stats := exam.Statistics()
+		g.statistics = exam.StatisticsTable()
+		g.distribution = exam.GradeDistributionTable()
+		g.passSummary = fmt.Sprintf("%d of %d passed (%.1f%%), mean grade %.2f", stats.Passed(), stats.Amount(), stats.PassRate(), stats.MeanGrade())
+		g.maxStudentPoints = stats.MaxPoints()
+	}
 	return nil
 }
 
@@ -67,14 +135,26 @@ func (g *GUI) applySettings() {
 		return
 	}
 
+	cmd := settingsCommand{oldMax: g.pMax, oldPass: g.pPass, newMax: maxVal, newPass: passVal}
+	if err := g.execute(cmd); err != nil {
+		dialog.ShowError(err, g.window)
+	}
+}
+
+// setPoints changes max and pass points and regrades the exam.
+func (g *GUI) setPoints(maxVal, passVal float64) error {
+	previousMax, previousPass := g.pMax, g.pPass
 	g.pMax = maxVal
 	g.pPass = passVal
 	if err := g.rebuildTables(); err != nil {
-		dialog.ShowError(fmt.Errorf("rebuild tables: %w", err), g.window)
-		return
+		g.pMax, g.pPass = previousMax, previousPass
+		return fmt.Errorf("rebuild tables: %w", err)
 	}
+	g.setSettingsText()
 	g.renderTables()
+	g.syncSliders()
 	g.statusLabel.SetText(fmt.Sprintf("Settings applied (max: %.1f, pass: %.1f)", g.pMax, g.pPass))
+	return nil
 }
 
 func (g *GUI) parseSettings() (float64, float64, error) {
@@ -99,6 +179,10 @@ func (g *GUI) parseSettings() (float64, float64, error) {
 }
 
 func (g *GUI) openCSVDialog() {
+	g.confirmDiscard(g.showOpenCSVDialog)
+}
+
+func (g *GUI) showOpenCSVDialog() {
 	fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
 		if err != nil {
 			dialog.ShowError(fmt.Errorf("open file dialog: %w", err), g.window)
@@ -120,6 +204,9 @@ func (g *GUI) openCSVDialog() {
 		}
 	}, g.window)
 	fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
+	if location := g.lastDirectory(); location != nil {
+		fileDialog.SetLocation(location)
+	}
 	fileDialog.Show()
 }
 
@@ -128,17 +215,25 @@ func (g *GUI) loadCSVPath(path string) error {
 	if err != nil {
 		return fmt.Errorf("read CSV: %w", err)
 	}
+	previous := g.loadState()
 	g.loadedTable = table
 	g.loadedCSVPath = path
+	g.selectedMatNr = ""
+	g.project.SetStudentFile(path)
 	if err := g.rebuildTables(); err != nil {
+		g.restoreLoadState(previous)
 		return fmt.Errorf("parse students: %w", err)
 	}
 	g.renderTables()
+	g.syncSliders()
+	g.clearHistory()
+	g.setDirty(false)
+	g.addRecentFile(path)
 	g.statusLabel.SetText(fmt.Sprintf("Loaded %s", g.loadedCSVPath))
-	return nil
+	return g.loadTaskPoints()
 }
 
-func (g *GUI) saveCSV() {
+func (g *GUI) saveResults() {
 	if g.gradedStudents == nil || g.gradingKey == nil {
 		dialog.ShowError(fmt.Errorf("no data loaded to save"), g.window)
 		return
@@ -148,10 +243,28 @@ func (g *GUI) saveCSV() {
 		return
 	}
 
-	basePath := strings.TrimSuffix(g.loadedCSVPath, filepath.Ext(g.loadedCSVPath))
-	gradingKeyPath := basePath + "-grading-key.csv"
-	gradedStudentsPath := basePath + "-graded.csv"
+	gradingKeyPath := g.project.OutputPath(project.OutputGradingKey)
+	gradedStudentsPath := g.project.OutputPath(project.OutputGraded)
+	paths := []string{gradingKeyPath, gradedStudentsPath}
 
+	existing := utilities.ExistingFiles(paths)
+	if len(existing) == 0 {
+		g.writeCSV(gradingKeyPath, gradedStudentsPath)
+		return
+	}
+	message := fmt.Sprintf("Overwrite existing files?\n%s", strings.Join(existing, "\n"))
+	dialog.ShowConfirm("Overwrite files", message, func(ok bool) {
+		if ok {
+			g.writeCSV(gradingKeyPath, gradedStudentsPath)
+		}
+	}, g.window)
+}
+
+func (g *GUI) writeCSV(gradingKeyPath, gradedStudentsPath string) {
+	if err := os.MkdirAll(filepath.Dir(gradingKeyPath), 0o755); err != nil {
+		dialog.ShowError(fmt.Errorf("create output folder: %w", err), g.window)
+		return
+	}
 	errGradingKey := g.gradingKey.ToCSV(gradingKeyPath)
 	errGradedStudents := g.gradedStudents.ToCSV(gradedStudentsPath)
 	if errGradingKey != nil || errGradedStudents != nil {
@@ -160,3 +273,171 @@ func (g *GUI) saveCSV() {
 	}
 	g.statusLabel.SetText(fmt.Sprintf("Saved %s and %s", gradingKeyPath, gradedStudentsPath))
 }
+
+func (g *GUI) openProjectDialog() {
+	g.confirmDiscard(g.showOpenProjectDialog)
+}
+
+func (g *GUI) showOpenProjectDialog() {
+	fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("open file dialog: %w", err), g.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		uri := reader.URI()
+		if uri == nil {
+			dialog.ShowError(fmt.Errorf("could not resolve selected file"), g.window)
+			return
+		}
+		if err := g.loadProjectPath(uri.Path()); err != nil {
+			dialog.ShowError(err, g.window)
+			return
+		}
+	}, g.window)
+	fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".toml", ".yaml", ".yml"}))
+	if location := g.lastDirectory(); location != nil {
+		fileDialog.SetLocation(location)
+	}
+	fileDialog.Show()
+}
+
+func (g *GUI) loadProjectPath(path string) error {
+	proj, err := project.Load(path)
+	if err != nil {
+		return err
+	}
+	previous := g.loadState()
+	g.project = proj
+	g.pMax = proj.PMax()
+	g.pPass = proj.PPass()
+	g.loadedTable = nil
+	g.loadedCSVPath = ""
+	g.selectedMatNr = ""
+	g.bonusTable = nil
+	g.correctionsTable = nil
+
+	if err := g.loadProjectFiles(); err != nil {
+		g.restoreLoadState(previous)
+		return err
+	}
+	if err := g.rebuildTables(); err != nil {
+		g.restoreLoadState(previous)
+		return fmt.Errorf("rebuild tables: %w", err)
+	}
+	g.setSettingsText()
+	g.renderTables()
+	g.syncSliders()
+	g.clearHistory()
+	g.setDirty(false)
+	g.ws.updateViewMenu()
+	if g.loadedCSVPath != "" {
+		g.addRecentFile(g.loadedCSVPath)
+	}
+	g.addRecentFile(path)
+	g.statusLabel.SetText(fmt.Sprintf("Loaded project %s", path))
+	return g.loadTaskPoints()
+}
+
+// loadProjectFiles reads the student, bonus and corrections files of the
+// project; the caller regrades once all of them are read.
+func (g *GUI) loadProjectFiles() error {
+	if strings.TrimSpace(g.project.BonusFile()) != "" {
+		table, err := utilities.ReadPlainCSV(g.project.BonusFile())
+		if err != nil {
+			return fmt.Errorf("read bonus CSV: %w", err)
+		}
+		g.bonusTable = table
+	}
+	if strings.TrimSpace(g.project.CorrectionsFile()) != "" {
+		table, err := utilities.ReadPlainCSV(g.project.CorrectionsFile())
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("read corrections log: %w", err)
+		}
+		if err == nil {
+			g.correctionsTable = table
+		}
+	}
+	if strings.TrimSpace(g.project.StudentFile()) != "" {
+		table, err := utilities.NewTableFromCSV(g.project.StudentFile())
+		if err != nil {
+			return fmt.Errorf("read CSV: %w", err)
+		}
+		g.loadedTable = table
+		g.loadedCSVPath = g.project.StudentFile()
+	}
+	return nil
+}
+
+// loadState is what a load replaces, kept to roll back a load that fails
+// after it started to change the tab. The graded tables are only replaced
+// by a successful rebuildTables.
+type loadState struct {
+	project          project.Project
+	pMax, pPass      float64
+	loadedTable      *utilities.Table
+	loadedCSVPath    string
+	selectedMatNr    string
+	bonusTable       *utilities.Table
+	correctionsTable *utilities.Table
+}
+
+func (g *GUI) loadState() loadState {
+	return loadState{
+		project:          g.project,
+		pMax:             g.pMax,
+		pPass:            g.pPass,
+		loadedTable:      g.loadedTable,
+		loadedCSVPath:    g.loadedCSVPath,
+		selectedMatNr:    g.selectedMatNr,
+		bonusTable:       g.bonusTable,
+		correctionsTable: g.correctionsTable,
+	}
+}
+
+func (g *GUI) restoreLoadState(s loadState) {
+	g.project = s.project
+	g.pMax, g.pPass = s.pMax, s.pPass
+	g.loadedTable = s.loadedTable
+	g.loadedCSVPath = s.loadedCSVPath
+	g.selectedMatNr = s.selectedMatNr
+	g.bonusTable = s.bonusTable
+	g.correctionsTable = s.correctionsTable
+}
+
+func (g *GUI) saveProjectDialog() {
+	g.savePathDialog("Save Project", g.projectFileName(), []string{".toml", ".yaml", ".yml"}, g.saveProjectPath)
+}
+
+func (g *GUI) projectFileName() string {
+	if strings.TrimSpace(g.project.Path()) != "" {
+		return filepath.Base(g.project.Path())
+	}
+	if strings.TrimSpace(g.loadedCSVPath) != "" {
+		return strings.TrimSuffix(filepath.Base(g.loadedCSVPath), filepath.Ext(g.loadedCSVPath)) + ".toml"
+	}
+	return "exam.toml"
+}
+
+// saveProjectPath validates the project with the current points before it
+// is written, and only a saved project replaces the one of the tab.
+func (g *GUI) saveProjectPath(path string) error {
+	proj := g.project
+	proj.SetPoints(g.pMax, g.pPass)
+	proj.SetStudentFile(g.loadedCSVPath)
+	if err := proj.Validate(); err != nil {
+		return err
+	}
+	if err := proj.Save(path); err != nil {
+		return err
+	}
+	proj.SetPath(path)
+	g.project = proj
+	g.addRecentFile(path)
+	g.statusLabel.SetText(fmt.Sprintf("Saved project %s", path))
+	return nil
+}
@@ -0,0 +1,334 @@
+package gui
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+// chart is a minimal plot widget: draw returns the canvas objects for a given
+// size and is called again on every layout and refresh.
+type chart struct {
+	widget.BaseWidget
+	draw func(size fyne.Size) []fyne.CanvasObject
+}
+
+func newChart(draw func(size fyne.Size) []fyne.CanvasObject) *chart {
+	c := &chart{draw: draw}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+func (c *chart) CreateRenderer() fyne.WidgetRenderer {
+	return &chartRenderer{chart: c}
+}
+
+type chartRenderer struct {
+	chart   *chart
+	objects []fyne.CanvasObject
+}
+
+func (r *chartRenderer) Layout(size fyne.Size) {
+	r.objects = r.chart.draw(size)
+}
+
+func (r *chartRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(chartMinWidth, chartMinHeight)
+}
+
+func (r *chartRenderer) Refresh() {
+	r.objects = r.chart.draw(r.chart.Size())
+	canvas.Refresh(r.chart)
+}
+
+func (r *chartRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *chartRenderer) Destroy() {}
+
+// chartData is what the charts need from the graded students and the grading
+// key tables.
+type chartData struct {
+	points    []float64
+	grades    []float64
+	keyPoints []float64
+	keyGrades []float64
+	pMax      float64
+	pPass     float64
+}
+
+func newChartData(graded, key *utilities.Table, pMax, pPass float64) chartData {
+	data := chartData{pMax: pMax, pPass: pPass}
+	if graded == nil || key == nil {
+		return data
+	}
+	data.points = floatColumn(graded, "Points")
+	data.grades = floatColumn(graded, "Grade")
+	data.keyPoints = floatColumn(key, "Points")
+	data.keyGrades = floatColumn(key, "Grade")
+	return data
+}
+
+func floatColumn(table *utilities.Table, header string) []float64 {
+	colIdx := table.ColumnIndex(header)
+	values := make([]float64, 0)
+	if colIdx < 0 {
+		return values
+	}
+	for _, row := range table.Rows() {
+		if colIdx < len(row) {
+			if v, ok := row[colIdx].(float64); ok {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+func (d chartData) empty() bool {
+	return len(d.points) == 0
+}
+
+func (d chartData) xMax() float64 {
+	xMax := d.pMax
+	for _, p := range d.points {
+		xMax = math.Max(xMax, p)
+	}
+	return xMax
+}
+
+// plotArea maps data coordinates into the chart, leaving room for the title
+// and the axis labels.
+type plotArea struct {
+	left, top, width, height float32
+}
+
+func newPlotArea(size fyne.Size) plotArea {
+	return plotArea{
+		left:   36,
+		top:    22,
+		width:  max(size.Width-44, 1),
+		height: max(size.Height-44, 1),
+	}
+}
+
+func (p plotArea) x(value, maxValue float64) float32 {
+	if maxValue <= 0 {
+		return p.left
+	}
+	return p.left + float32(value/maxValue)*p.width
+}
+
+func (p plotArea) y(value, maxValue float64) float32 {
+	if maxValue <= 0 {
+		return p.top + p.height
+	}
+	return p.top + p.height - float32(value/maxValue)*p.height
+}
+
+func (p plotArea) bottom() float32 {
+	return p.top + p.height
+}
+
+func (p plotArea) axes(title string) []fyne.CanvasObject {
+	fg := theme.Color(theme.ColorNameForeground)
+	return []fyne.CanvasObject{
+		chartText(title, fyne.NewPos(p.left, 2), fg, true),
+		chartLine(fyne.NewPos(p.left, p.top), fyne.NewPos(p.left, p.bottom()), fg, 1),
+		chartLine(fyne.NewPos(p.left, p.bottom()), fyne.NewPos(p.left+p.width, p.bottom()), fg, 1),
+	}
+}
+
+func chartLine(from, to fyne.Position, c color.Color, width float32) *canvas.Line {
+	line := canvas.NewLine(c)
+	line.Position1 = from
+	line.Position2 = to
+	line.StrokeWidth = width
+	return line
+}
+
+func chartBar(left, top, width, height float32, c color.Color) *canvas.Rectangle {
+	bar := canvas.NewRectangle(c)
+	bar.Move(fyne.NewPos(left, top))
+	bar.Resize(fyne.NewSize(max(width, 1), max(height, 0)))
+	return bar
+}
+
+func chartText(text string, pos fyne.Position, c color.Color, bold bool) *canvas.Text {
+	label := canvas.NewText(text, c)
+	label.TextSize = theme.Size(theme.SizeNameCaptionText)
+	label.TextStyle = fyne.TextStyle{Bold: bold}
+	label.Move(pos)
+	return label
+}
+
+func (d chartData) noData(size fyne.Size, title string) []fyne.CanvasObject {
+	area := newPlotArea(size)
+	objects := area.axes(title)
+	return append(objects, chartText("no students loaded", fyne.NewPos(area.left+8, area.top+8), theme.Color(theme.ColorNameDisabled), false))
+}
+
+// drawGradeHistogram draws one bar per step of the grading key.
+func (d chartData) drawGradeHistogram(size fyne.Size) []fyne.CanvasObject {
+	title := "Grades"
+	if d.empty() {
+		return d.noData(size, title)
+	}
+
+	steps := uniqueSorted(d.keyGrades)
+	if len(steps) == 0 {
+		return d.noData(size, title)
+	}
+	counts := make(map[float64]int)
+	maxCount := 0
+	for _, grade := range d.grades {
+		counts[grade]++
+		maxCount = max(maxCount, counts[grade])
+	}
+
+	area := newPlotArea(size)
+	objects := area.axes(title)
+	fg := theme.Color(theme.ColorNameForeground)
+	slot := area.width / float32(len(steps))
+	for i, grade := range steps {
+		barColor := theme.Color(theme.ColorNamePrimary)
+		if grade > 4.0 {
+			barColor = theme.Color(theme.ColorNameError)
+		}
+		top := area.y(float64(counts[grade]), float64(maxCount))
+		left := area.left + float32(i)*slot + slot*0.15
+		objects = append(objects,
+			chartBar(left, top, slot*0.7, area.bottom()-top, barColor),
+			chartText(fmt.Sprintf("%.1f", grade), fyne.NewPos(left, area.bottom()+2), fg, false),
+		)
+		if counts[grade] > 0 {
+			objects = append(objects, chartText(fmt.Sprintf("%d", counts[grade]), fyne.NewPos(left, top-14), fg, false))
+		}
+	}
+	return objects
+}
+
+// drawPointsDistribution draws a histogram of the points with the key
+// thresholds and the pass line.
+func (d chartData) drawPointsDistribution(size fyne.Size) []fyne.CanvasObject {
+	title := "Points"
+	if d.empty() {
+		return d.noData(size, title)
+	}
+
+	xMax := d.xMax()
+	binWidth := xMax / chartBins
+	counts := make([]int, chartBins)
+	maxCount := 0
+	for _, p := range d.points {
+		bin := min(int(p/binWidth), chartBins-1)
+		counts[max(bin, 0)]++
+		maxCount = max(maxCount, counts[max(bin, 0)])
+	}
+
+	area := newPlotArea(size)
+	objects := area.axes(title)
+	for _, threshold := range d.keyPoints {
+		if threshold <= 0 {
+			continue
+		}
+		x := area.x(threshold, xMax)
+		objects = append(objects, chartLine(fyne.NewPos(x, area.top), fyne.NewPos(x, area.bottom()), theme.Color(theme.ColorNameDisabled), 1))
+	}
+	for bin, count := range counts {
+		top := area.y(float64(count), float64(maxCount))
+		left := area.x(float64(bin)*binWidth, xMax)
+		objects = append(objects, chartBar(left+1, top, area.width/chartBins-2, area.bottom()-top, theme.Color(theme.ColorNamePrimary)))
+	}
+	return append(objects, d.axisLabels(area, xMax)...)
+}
+
+// drawCumulative draws the share of students with at most the given points.
+func (d chartData) drawCumulative(size fyne.Size) []fyne.CanvasObject {
+	title := "Cumulative"
+	if d.empty() {
+		return d.noData(size, title)
+	}
+
+	xMax := d.xMax()
+	points := append([]float64(nil), d.points...)
+	sort.Float64s(points)
+
+	area := newPlotArea(size)
+	objects := area.axes(title)
+	primary := theme.Color(theme.ColorNamePrimary)
+	last := fyne.NewPos(area.left, area.bottom())
+	for i, p := range points {
+		x := area.x(p, xMax)
+		y := area.y(float64(i+1), float64(len(points)))
+		objects = append(objects,
+			chartLine(last, fyne.NewPos(x, last.Y), primary, 2),
+			chartLine(fyne.NewPos(x, last.Y), fyne.NewPos(x, y), primary, 2),
+		)
+		last = fyne.NewPos(x, y)
+	}
+	objects = append(objects, chartLine(last, fyne.NewPos(area.left+area.width, last.Y), primary, 2))
+
+	fg := theme.Color(theme.ColorNameForeground)
+	objects = append(objects,
+		chartText("100%", fyne.NewPos(2, area.top-6), fg, false),
+		chartText("0%", fyne.NewPos(12, area.bottom()-12), fg, false),
+	)
+	return append(objects, d.axisLabels(area, xMax)...)
+}
+
+func (d chartData) axisLabels(area plotArea, xMax float64) []fyne.CanvasObject {
+	fg := theme.Color(theme.ColorNameForeground)
+	pass := area.x(d.pPass, xMax)
+	return []fyne.CanvasObject{
+		chartLine(fyne.NewPos(pass, area.top), fyne.NewPos(pass, area.bottom()), theme.Color(theme.ColorNameError), 2),
+		chartText(fmt.Sprintf("pass %.1f", d.pPass), fyne.NewPos(pass+2, area.top), theme.Color(theme.ColorNameError), false),
+		chartText("0", fyne.NewPos(area.left, area.bottom()+2), fg, false),
+		chartText(fmt.Sprintf("%.0f", xMax), fyne.NewPos(area.left+area.width-16, area.bottom()+2), fg, false),
+	}
+}
+
+func uniqueSorted(values []float64) []float64 {
+	seen := make(map[float64]bool)
+	unique := make([]float64, 0)
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	sort.Float64s(unique)
+	return unique
+}
+
+func (g *GUI) newCharts() {
+	g.charts = []*chart{
+		newChart(func(size fyne.Size) []fyne.CanvasObject { return g.chartData.drawGradeHistogram(size) }),
+		newChart(func(size fyne.Size) []fyne.CanvasObject { return g.chartData.drawPointsDistribution(size) }),
+		newChart(func(size fyne.Size) []fyne.CanvasObject { return g.chartData.drawCumulative(size) }),
+	}
+}
+
+func (g *GUI) buildCharts() fyne.CanvasObject {
+	objects := make([]fyne.CanvasObject, 0, len(g.charts))
+	for _, c := range g.charts {
+		objects = append(objects, c)
+	}
+	return container.NewGridWithColumns(len(objects), objects...)
+}
+
+func (g *GUI) renderCharts() {
+	g.chartData = newChartData(g.gradedStudents, g.gradingKey, g.pMax, g.pPass)
+	for _, c := range g.charts {
+		c.Refresh()
+	}
+}
@@ -0,0 +1,79 @@
+package gui
+
+import (
+	"fmt"
+	"slices"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/andreaswillibaldweber/gogrades/internal/grades"
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+func (ws *workspace) buildCompareTab() *container.TabItem {
+	ws.compareTable = newTableAdapter(func(colIdx int) bool {
+		return colIdx >= 2
+	})
+	ws.compareTable.sortable = true
+	ws.compareLabel = widget.NewLabel("")
+	content := container.NewBorder(ws.compareLabel, nil, nil, nil, ws.compareTable.Widget())
+	return container.NewTabItem("Comparison", content)
+}
+
+func (ws *workspace) showComparison() {
+	if !ws.comparisonShown() {
+		ws.tabs.Append(ws.compareTab)
+	}
+	ws.tabs.Select(ws.compareTab)
+	ws.renderComparison()
+}
+
+func (ws *workspace) comparisonShown() bool {
+	return slices.Contains(ws.tabs.Items, ws.compareTab)
+}
+
+// renderComparison joins the graded students of all exam tabs by
+// matriculation number. Tabs with the same name are numbered.
+func (ws *workspace) renderComparison() {
+	names := make([]string, 0, len(ws.exams))
+	tables := make([]*utilities.Table, 0, len(ws.exams))
+	seen := make(map[string]int)
+	for _, g := range ws.exams {
+		if g.gradedStudents == nil || len(g.gradedStudents.Rows()) == 0 {
+			continue
+		}
+		name := g.tabName()
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s (%d)", name, seen[name])
+		}
+		names = append(names, name)
+		tables = append(tables, g.gradedStudents)
+	}
+	if len(tables) < 2 {
+		ws.compareLabel.SetText("Open at least two exams with students in tabs to compare them.")
+		ws.compareTable.clear()
+		return
+	}
+	table, notes, err := grades.CompareGradedTables(names, tables)
+	if err != nil {
+		ws.compareLabel.SetText(fmt.Sprintf("Compare exams: %v", err))
+		ws.compareTable.clear()
+		return
+	}
+	text := fmt.Sprintf("%d students in %d exams", len(table.Rows()), len(tables)-len(notes))
+	for _, note := range notes {
+		text += "\n" + note
+	}
+	ws.compareLabel.SetText(text)
+	ws.compareTable.setData(table, formatCompareCell)
+	ws.compareTable.resizeToFit(ws.window.Canvas().Size().Width - 24)
+	ws.compareTable.Widget().Refresh()
+}
+
+func formatCompareCell(colIdx int, value any) string {
+	if v, ok := value.(float64); ok {
+		return fmt.Sprintf("%.1f", v)
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -1,10 +1,25 @@
 package gui
 
+import "time"
+
 const (
 	windowWidth      = 1200
 	windowHeight     = 700
 	splitOffset      = 0.72
+	sideOffset       = 0.78
+	sliderMaxPoints  = 100.0
+	sliderStep       = 0.5
+	sliderWidth      = 160
+	chartOffset      = 0.68
+	chartMinWidth    = 160
+	chartMinHeight   = 140
+	chartBins        = 20
+	nearPassPoints   = 2.0
+	highlightAlpha   = 0x50
 	approxCharWidth  = 8.0
 	cellPadding      = 24.0
 	defaultRowHeader = 56.0
+	recentFilesMax   = 8
+	autosaveInterval = 2 * time.Minute
+	saveDialogWidth  = 520
 )
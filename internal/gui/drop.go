@@ -0,0 +1,205 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"github.com/andreaswillibaldweber/gogrades/internal/project"
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+// filesDropped loads one dropped file or offers to merge several student
+// files, e.g. the lists of different exam rooms.
+func (g *GUI) filesDropped(_ fyne.Position, uris []fyne.URI) {
+	paths := make([]string, 0, len(uris))
+	for _, uri := range uris {
+		if uri.Scheme() == "file" {
+			paths = append(paths, uri.Path())
+		}
+	}
+	switch len(paths) {
+	case 0:
+		return
+	case 1:
+		g.confirmDiscard(func() { g.openDroppedFile(paths[0]) })
+	default:
+		g.confirmDiscard(func() { g.mergeDialog(paths) })
+	}
+}
+
+func (g *GUI) openDroppedFile(path string) {
+	var err error
+	switch ext := strings.ToLower(filepath.Ext(path)); {
+	case ext == ".csv":
+		err = g.loadCSVPath(path)
+	case project.IsProjectFile(path):
+		err = g.loadProjectPath(path)
+	case ext == ".xlsx":
+		err = g.importXLSX(path)
+	case ext == ".xls":
+		err = fmt.Errorf("%s: old Excel files are not supported, save the sheet as .xlsx or CSV first", filepath.Base(path))
+	default:
+		err = fmt.Errorf("%s: unsupported file (use .csv, .xlsx, .toml, .yaml or .yml)", filepath.Base(path))
+	}
+	if err != nil {
+		dialog.ShowError(err, g.window)
+	}
+}
+
+// importXLSX shows the students of an Excel sheet as a new, unsaved student
+// list, so that saving never writes CSV into the workbook.
+func (g *GUI) importXLSX(path string) error {
+	table, err := utilities.ReadXLSX(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+	return g.loadUnsavedTable(table, fmt.Sprintf("Imported %d students from %s, save them as CSV with File -> Save As...", len(table.Rows()), filepath.Base(path)))
+}
+
+func (g *GUI) mergeDialog(paths []string) {
+	tables, err := readStudentFiles(paths)
+	if err != nil {
+		dialog.ShowError(err, g.window)
+		return
+	}
+	if missing := emptyMatNrs(paths, tables); len(missing) > 0 {
+		dialog.ShowError(fmt.Errorf("cannot merge, students without matriculation number:\n%s", strings.Join(missing, "\n")), g.window)
+		return
+	}
+	if duplicates := duplicateMatNrs(paths, tables); len(duplicates) > 0 {
+		dialog.ShowError(fmt.Errorf("cannot merge, duplicate matriculation numbers:\n%s", strings.Join(duplicates, "\n")), g.window)
+		return
+	}
+
+	lines := make([]string, len(paths))
+	total := 0
+	for i, path := range paths {
+		lines[i] = fmt.Sprintf("%s (%d students)", filepath.Base(path), len(tables[i].Rows()))
+		total += len(tables[i].Rows())
+	}
+	message := fmt.Sprintf("Merge %d student files with %d students?\n%s", len(paths), total, strings.Join(lines, "\n"))
+	dialog.ShowConfirm("Merge student files", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := g.loadMergedTables(tables); err != nil {
+			dialog.ShowError(err, g.window)
+		}
+	}, g.window)
+}
+
+func readStudentFiles(paths []string) ([]*utilities.Table, error) {
+	tables := make([]*utilities.Table, len(paths))
+	for i, path := range paths {
+		var table *utilities.Table
+		var err error
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			table, err = utilities.NewTableFromCSV(path)
+		case ".xlsx":
+			table, err = utilities.ReadXLSX(path)
+		default:
+			return nil, fmt.Errorf("%s: only CSV and XLSX student files can be merged", filepath.Base(path))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+		}
+		if i > 0 {
+			if err := sameColumns(tables[0].Headers(), table.Headers()); err != nil {
+				return nil, fmt.Errorf("%s and %s: %w", filepath.Base(paths[0]), filepath.Base(path), err)
+			}
+		}
+		tables[i] = table
+	}
+	return tables, nil
+}
+
+// sameColumns compares the header names, ignoring case and surrounding
+// spaces, so that files with swapped columns are not merged row by row.
+func sameColumns(first, other []string) error {
+	if len(first) != len(other) {
+		return fmt.Errorf("%d and %d columns", len(first), len(other))
+	}
+	for col := range first {
+		if !strings.EqualFold(strings.TrimSpace(first[col]), strings.TrimSpace(other[col])) {
+			return fmt.Errorf("column %d is %q and %q", col+1, first[col], other[col])
+		}
+	}
+	return nil
+}
+
+// emptyMatNrs lists the students without matriculation number, which can
+// neither be checked for duplicates nor graded.
+func emptyMatNrs(paths []string, tables []*utilities.Table) []string {
+	missing := make([]string, 0)
+	for i, table := range tables {
+		for row := range table.Rows() {
+			if strings.TrimSpace(cellText(table, row, studentColMatNr)) == "" {
+				missing = append(missing, fmt.Sprintf("%s: student %d (%s)", filepath.Base(paths[i]), row+1, cellText(table, row, studentColName)))
+			}
+		}
+	}
+	return missing
+}
+
+// duplicateMatNrs lists every matriculation number found more than once,
+// within one file or across files, with the files it appears in. Empty
+// numbers are left to emptyMatNrs.
+func duplicateMatNrs(paths []string, tables []*utilities.Table) []string {
+	files := make(map[string][]string)
+	for i, table := range tables {
+		for row := range table.Rows() {
+			matNr := strings.TrimSpace(cellText(table, row, studentColMatNr))
+			if matNr == "" {
+				continue
+			}
+			files[matNr] = append(files[matNr], filepath.Base(paths[i]))
+		}
+	}
+	duplicates := make([]string, 0)
+	for matNr, in := range files {
+		if len(in) > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("%s (%s)", matNr, strings.Join(in, ", ")))
+		}
+	}
+	sort.Strings(duplicates)
+	return duplicates
+}
+
+// loadMergedTables shows the merged students as a new, unsaved student list.
+func (g *GUI) loadMergedTables(tables []*utilities.Table) error {
+	if len(tables) == 0 {
+		return errors.New("no student files to merge")
+	}
+	rows := make([]utilities.TableRow, 0)
+	for _, table := range tables {
+		rows = append(rows, table.Rows()...)
+	}
+	merged := utilities.NewTable(tables[0].Headers(), rows)
+	return g.loadUnsavedTable(merged, fmt.Sprintf("Merged %d files with %d students, save them with File -> Save As...", len(tables), len(rows)))
+}
+
+// loadUnsavedTable shows students that have no CSV file yet.
+func (g *GUI) loadUnsavedTable(table *utilities.Table, status string) error {
+	previous := g.loadState()
+	g.loadedTable = table
+	g.loadedCSVPath = ""
+	g.selectedMatNr = ""
+	g.project.SetStudentFile("")
+	if err := g.rebuildTables(); err != nil {
+		g.restoreLoadState(previous)
+		return fmt.Errorf("parse students: %w", err)
+	}
+	g.taskPoints = nil
+	g.renderTables()
+	g.syncSliders()
+	g.clearHistory()
+	g.setDirty(true)
+	g.statusLabel.SetText(status)
+	return nil
+}
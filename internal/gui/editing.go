@@ -0,0 +1,418 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/andreaswillibaldweber/gogrades/internal/grades"
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+// Columns of the loaded student CSV file.
+const (
+	studentColName = iota
+	studentColMatNr
+	studentColSeat
+	studentColPoints
+	studentColComment
+)
+
+var studentHeaders = []string{"Name", "Mat-Nr", "Seat-Nr", "Points", "Comment"}
+
+// editableColumns maps the editable columns of the graded students table to
+// the columns of the loaded student file.
+var editableColumns = map[int]int{
+	0: studentColName,
+	2: studentColSeat,
+	3: studentColPoints,
+	6: studentColComment,
+}
+
+var matNrPattern = regexp.MustCompile(grades.DefaultMatNrPattern)
+
+func (g *GUI) buildStudentToolbar() fyne.CanvasObject {
+	return widget.NewToolbar(
+		widget.NewToolbarAction(theme.ContentAddIcon(), g.addStudentDialog),
+		widget.NewToolbarAction(theme.DeleteIcon(), g.deleteSelectedStudent),
+		widget.NewToolbarSeparator(),
+		widget.NewToolbarAction(theme.DocumentSaveIcon(), g.saveStudents),
+	)
+}
+
+func (g *GUI) gradedCellSelected(row, col int) {
+	g.gradedTable.Widget().UnselectAll()
+	if g.gradedStudents == nil || row < 0 || row >= len(g.gradedStudents.Rows()) {
+		return
+	}
+	g.selectedMatNr = fmt.Sprintf("%v", g.gradedStudents.Rows()[row][1])
+	g.statusLabel.SetText(fmt.Sprintf("Selected %s", g.selectedMatNr))
+	if _, ok := editableColumns[col]; ok {
+		g.editCellDialog(g.selectedMatNr, col)
+	}
+}
+
+func (g *GUI) editCellDialog(matNr string, gradedCol int) {
+	srcRow := g.studentRow(matNr)
+	if srcRow < 0 {
+		return
+	}
+	srcCol := editableColumns[gradedCol]
+	item := widget.NewFormItem(studentHeaders[srcCol], nil)
+	if srcCol == studentColPoints {
+		if g.correctedError(matNr) != nil {
+			g.correctionDialog(matNr)
+			return
+		}
+		item.Text = "Imported Points"
+		if shown, ok := g.gradedPoints(matNr); ok && shown != cellValueAt(g.loadedTable, srcRow, srcCol) {
+			item.HintText = fmt.Sprintf("the table shows %.1f including the bonus", shown)
+		}
+	}
+
+	entry := widget.NewEntry()
+	entry.SetText(cellText(g.loadedTable, srcRow, srcCol))
+	entry.Validator = g.cellValidator(srcCol)
+	item.Widget = entry
+	items := []*widget.FormItem{item}
+	dialog.ShowForm(fmt.Sprintf("Edit student %s", matNr), "Apply", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		cmd := setCellCommand{matNr: matNr, col: srcCol, old: cellValueAt(g.loadedTable, srcRow, srcCol), new: cellValue(srcCol, entry.Text)}
+		if err := g.execute(cmd); err != nil {
+			dialog.ShowError(err, g.window)
+		}
+	}, g.window)
+}
+
+func (g *GUI) addStudentDialog() {
+	entries := make([]*widget.Entry, len(studentHeaders))
+	items := make([]*widget.FormItem, len(studentHeaders))
+	for col, header := range studentHeaders {
+		entries[col] = widget.NewEntry()
+		entries[col].Validator = g.cellValidator(col)
+		items[col] = widget.NewFormItem(header, entries[col])
+	}
+	entries[studentColMatNr].Validator = g.validateNewMatNr
+
+	dialog.ShowForm("Add student", "Add", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		row := make(utilities.TableRow, len(entries))
+		for col, entry := range entries {
+			row[col] = cellValue(col, entry.Text)
+		}
+		if err := g.execute(addRowCommand{index: len(g.studentRows()), row: row}); err != nil {
+			dialog.ShowError(err, g.window)
+		}
+	}, g.window)
+}
+
+func (g *GUI) deleteSelectedStudent() {
+	srcRow := g.studentRow(g.selectedMatNr)
+	if srcRow < 0 {
+		dialog.ShowError(errors.New("select a student in the graded students table first"), g.window)
+		return
+	}
+	if err := g.correctedError(g.selectedMatNr); err != nil {
+		dialog.ShowError(fmt.Errorf("%w, so the student cannot be deleted", err), g.window)
+		return
+	}
+	name := cellText(g.loadedTable, srcRow, studentColName)
+	message := fmt.Sprintf("Delete %s (%s)?", name, g.selectedMatNr)
+	dialog.ShowConfirm("Delete student", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		row := append(utilities.TableRow(nil), g.loadedTable.Rows()[srcRow]...)
+		if err := g.execute(deleteRowCommand{index: srcRow, row: row}); err != nil {
+			dialog.ShowError(err, g.window)
+		}
+	}, g.window)
+}
+
+// correctedError refuses to change the imported points of a student with
+// entries in the corrections log, or to delete the student: the log is
+// replayed on top of the imported points and must start from the points it
+// was written for.
+func (g *GUI) correctedError(matNr string) error {
+	if g.correctionsTable == nil {
+		return nil
+	}
+	for row := range g.correctionsTable.Rows() {
+		if strings.TrimSpace(cellText(g.correctionsTable, row, 0)) == matNr {
+			return fmt.Errorf("%s has entries in the corrections log %s", matNr, filepath.Base(g.project.CorrectionsFile()))
+		}
+	}
+	return nil
+}
+
+// correctionDialog changes the points of a corrected student by appending
+// another entry to the corrections log. The log is the record for the exam
+// board, so the entry cannot be undone.
+func (g *GUI) correctionDialog(matNr string) {
+	points := widget.NewEntry()
+	if row := g.studentRow(matNr); row >= 0 {
+		points.SetPlaceHolder(fmt.Sprintf("imported %s", cellText(g.loadedTable, row, studentColPoints)))
+	}
+	points.Validator = g.cellValidator(studentColPoints)
+	required := func(text string) error {
+		if strings.TrimSpace(text) == "" {
+			return errors.New("must not be empty")
+		}
+		return nil
+	}
+	reason := widget.NewEntry()
+	reason.Validator = required
+	examiner := widget.NewEntry()
+	examiner.Validator = required
+
+	pointsItem := widget.NewFormItem("Corrected Points", points)
+	pointsItem.HintText = "before bonus; the entry is added to the corrections log and cannot be undone"
+	items := []*widget.FormItem{
+		pointsItem,
+		widget.NewFormItem("Reason", reason),
+		widget.NewFormItem("Examiner", examiner),
+	}
+	dialog.ShowForm(fmt.Sprintf("Correct student %s", matNr), "Log Correction", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		value, _ := cellValue(studentColPoints, points.Text).(float64)
+		if err := g.logCorrection(matNr, value, strings.TrimSpace(reason.Text), strings.TrimSpace(examiner.Text)); err != nil {
+			dialog.ShowError(err, g.window)
+			return
+		}
+		if err := g.reloadCorrections(); err != nil {
+			dialog.ShowError(err, g.window)
+			return
+		}
+		g.statusLabel.SetText(fmt.Sprintf("Logged correction for %s in %s", matNr, filepath.Base(g.project.CorrectionsFile())))
+	}, g.window)
+}
+
+func (g *GUI) reloadCorrections() error {
+	table, err := utilities.ReadPlainCSV(g.project.CorrectionsFile())
+	if err != nil {
+		return fmt.Errorf("read corrections log: %w", err)
+	}
+	previous := g.correctionsTable
+	g.correctionsTable = table
+	if err := g.rebuildTables(); err != nil {
+		g.correctionsTable = previous
+		return fmt.Errorf("rebuild tables: %w", err)
+	}
+	g.renderTables()
+	return nil
+}
+
+// gradedPoints are the points the graded students table shows, i.e. after
+// corrections and bonus.
+func (g *GUI) gradedPoints(matNr string) (float64, bool) {
+	if g.gradedStudents == nil {
+		return 0, false
+	}
+	for _, row := range g.gradedStudents.Rows() {
+		if len(row) > 3 && fmt.Sprintf("%v", row[1]) == matNr {
+			points, ok := row[3].(float64)
+			return points, ok
+		}
+	}
+	return 0, false
+}
+
+func (g *GUI) setStudentCell(srcRow, srcCol int, value any) error {
+	if srcRow < 0 || srcRow >= len(g.studentRows()) {
+		return fmt.Errorf("no student in row %d", srcRow+1)
+	}
+	previous := append(utilities.TableRow(nil), g.loadedTable.Rows()[srcRow]...)
+	g.loadedTable.SetCell(srcRow, srcCol, value)
+	if err := g.rebuildTables(); err != nil {
+		g.loadedTable.Rows()[srcRow] = previous
+		return fmt.Errorf("rebuild tables: %w", err)
+	}
+	g.renderTables()
+	g.setDirty(true)
+	return nil
+}
+
+func (g *GUI) addStudentRow(index int, row utilities.TableRow) error {
+	if g.loadedTable == nil {
+		g.loadedTable = utilities.NewEmptyTable(append([]string(nil), studentHeaders...))
+	}
+	rows := g.loadedTable.Rows()
+	index = min(max(index, 0), len(rows))
+	updated := make([]utilities.TableRow, 0, len(rows)+1)
+	updated = append(updated, rows[:index]...)
+	updated = append(updated, row)
+	updated = append(updated, rows[index:]...)
+	g.loadedTable.SetRows(updated)
+
+	if err := g.rebuildTables(); err != nil {
+		g.loadedTable.SetRows(rows)
+		return fmt.Errorf("rebuild tables: %w", err)
+	}
+	g.renderTables()
+	g.setDirty(true)
+	g.statusLabel.SetText(fmt.Sprintf("Added %v", row[studentColMatNr]))
+	return nil
+}
+
+func (g *GUI) deleteStudentRow(index int) error {
+	rows := g.studentRows()
+	if index < 0 || index >= len(rows) {
+		return fmt.Errorf("no student in row %d", index+1)
+	}
+	matNr := fmt.Sprintf("%v", rows[index][studentColMatNr])
+	previous := append([]utilities.TableRow(nil), rows...)
+	g.loadedTable.DeleteRow(index)
+
+	if err := g.rebuildTables(); err != nil {
+		g.loadedTable.SetRows(previous)
+		return fmt.Errorf("rebuild tables: %w", err)
+	}
+	if g.selectedMatNr == matNr {
+		g.selectedMatNr = ""
+	}
+	g.renderTables()
+	g.setDirty(true)
+	g.statusLabel.SetText(fmt.Sprintf("Deleted %s", matNr))
+	return nil
+}
+
+func (g *GUI) studentRows() []utilities.TableRow {
+	if g.loadedTable == nil {
+		return nil
+	}
+	return g.loadedTable.Rows()
+}
+
+func (g *GUI) studentRow(matNr string) int {
+	if strings.TrimSpace(matNr) == "" {
+		return -1
+	}
+	for i, row := range g.studentRows() {
+		if len(row) > studentColMatNr && strings.TrimSpace(fmt.Sprintf("%v", row[studentColMatNr])) == matNr {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *GUI) cellValidator(srcCol int) fyne.StringValidator {
+	switch srcCol {
+	case studentColName:
+		return func(text string) error {
+			if strings.TrimSpace(text) == "" {
+				return errors.New("name must not be empty")
+			}
+			return nil
+		}
+	case studentColPoints:
+		return func(text string) error {
+			points, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+			if err != nil {
+				return errors.New("points must be a number, e.g. 42.5")
+			}
+			if points < 0 || points > g.pMax {
+				return fmt.Errorf("points must be between 0 and %.1f", g.pMax)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (g *GUI) validateNewMatNr(text string) error {
+	matNr := strings.TrimSpace(text)
+	if !matNrPattern.MatchString(matNr) {
+		return fmt.Errorf("matriculation number must match %s", grades.DefaultMatNrPattern)
+	}
+	if g.studentRow(matNr) >= 0 {
+		return fmt.Errorf("matriculation number %s already exists", matNr)
+	}
+	return nil
+}
+
+func (g *GUI) saveStudents() {
+	if g.loadedTable == nil {
+		dialog.ShowError(errors.New("no students to save"), g.window)
+		return
+	}
+	if strings.TrimSpace(g.loadedCSVPath) == "" {
+		dialog.ShowError(errors.New("missing student CSV path, save the student list with File -> Save As... first"), g.window)
+		return
+	}
+	if err := g.loadedTable.ToCSV(g.loadedCSVPath); err != nil {
+		dialog.ShowError(fmt.Errorf("save students: %w", err), g.window)
+		return
+	}
+	if err := g.saveTaskPoints(); err != nil {
+		dialog.ShowError(err, g.window)
+		return
+	}
+	g.setDirty(false)
+	g.removeRecovery()
+	g.statusLabel.SetText(fmt.Sprintf("Saved students to %s", g.loadedCSVPath))
+}
+
+func (g *GUI) setDirty(dirty bool) {
+	g.dirty = dirty
+	title := "GoGrades"
+	if strings.TrimSpace(g.loadedCSVPath) != "" {
+		title += " - " + filepath.Base(g.loadedCSVPath)
+	}
+	name := g.tabName()
+	if dirty {
+		title += " *"
+		name += " *"
+	}
+	if g.tab != nil && g.tab.Text != name {
+		g.tab.Text = name
+		g.ws.tabs.Refresh()
+	}
+	if g.isActive() {
+		g.window.SetTitle(title)
+	}
+}
+
+func (g *GUI) buildStudentHeader() fyne.CanvasObject {
+	return container.NewVBox(
+		container.NewHBox(widget.NewLabel("Graded Students"), g.buildStudentToolbar()),
+		g.buildFilterBar(),
+	)
+}
+
+func cellText(table *utilities.Table, row, col int) string {
+	if table == nil || row < 0 || row >= len(table.Rows()) || col >= len(table.Rows()[row]) {
+		return ""
+	}
+	return fmt.Sprintf("%v", table.Rows()[row][col])
+}
+
+func cellValueAt(table *utilities.Table, row, col int) any {
+	if table == nil || row < 0 || row >= len(table.Rows()) || col >= len(table.Rows()[row]) {
+		return ""
+	}
+	return table.Rows()[row][col]
+}
+
+func cellValue(srcCol int, text string) any {
+	text = strings.TrimSpace(text)
+	if srcCol == studentColPoints {
+		if points, err := strconv.ParseFloat(text, 64); err == nil {
+			return points
+		}
+	}
+	return text
+}
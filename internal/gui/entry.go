@@ -0,0 +1,347 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/andreaswillibaldweber/gogrades/internal/project"
+)
+
+// Orders of the point entry mode: by seat, or as the students are listed in
+// the student file, e.g. in the order the exams were scanned.
+const (
+	entryOrderSeat = "Seat order"
+	entryOrderScan = "Scan order"
+)
+
+// pointEntry steps through the students to type in the points of every task
+// live from the paper exams. Tab moves to the next task, Enter on the last
+// task stores the total and moves to the next student.
+type pointEntry struct {
+	g       *GUI
+	dialog  *dialog.CustomDialog
+	tasks   []project.Task
+	order   []string
+	index   int
+	entries []*widget.Entry
+	student *widget.Label
+	total   *widget.Label
+	message *widget.Label
+	jump    *widget.Entry
+}
+
+func (g *GUI) pointEntryDialog() {
+	if len(g.studentRows()) == 0 {
+		dialog.ShowError(errors.New("no students loaded, open a student CSV first"), g.window)
+		return
+	}
+	e := &pointEntry{
+		g:       g,
+		tasks:   g.project.Tasks(),
+		student: widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		total:   widget.NewLabel(""),
+		message: widget.NewLabel(""),
+		jump:    widget.NewEntry(),
+	}
+	if len(e.tasks) == 0 {
+		e.tasks = []project.Task{project.NewTask("Points", g.pMax)}
+	}
+	e.message.Importance = widget.DangerImportance
+
+	form := widget.NewForm()
+	for i, task := range e.tasks {
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder(fmt.Sprintf("0 - %.1f", task.Max()))
+		entry.OnChanged = func(string) { e.updateTotal() }
+		entry.OnSubmitted = func(string) { e.submitted(i) }
+		e.entries = append(e.entries, entry)
+		form.Append(fmt.Sprintf("%s (max %.1f)", task.Name(), task.Max()), entry)
+	}
+
+	e.jump.SetPlaceHolder("Mat-Nr + Enter")
+	e.jump.OnSubmitted = e.jumpTo
+	orderSelect := widget.NewSelect([]string{entryOrderSeat, entryOrderScan}, e.setOrder)
+
+	header := container.NewHBox(
+		widget.NewLabel("Order"), orderSelect,
+		widget.NewLabel("Jump to"), container.NewGridWrap(fyne.NewSize(140, e.jump.MinSize().Height), e.jump),
+	)
+	content := container.NewVBox(header, widget.NewSeparator(), e.student, form, e.total, e.message)
+
+	previous := widget.NewButton("Previous", func() { e.move(-1) })
+	next := widget.NewButton("Next", func() { e.move(1) })
+	next.Importance = widget.HighImportance
+	closeButton := widget.NewButton("Close", e.close)
+	e.dialog = dialog.NewCustomWithoutButtons("Point Entry", content, g.window)
+	e.dialog.SetButtons([]fyne.CanvasObject{closeButton, previous, next})
+
+	orderSelect.SetSelected(g.preferences().StringWithFallback(prefEntryOrder, entryOrderSeat))
+	e.dialog.Show()
+	e.focus(0)
+}
+
+// setOrder sorts the students and stays with the current one.
+func (e *pointEntry) setOrder(order string) {
+	current := ""
+	if e.index < len(e.order) {
+		current = e.order[e.index]
+	}
+	rows := e.g.studentRows()
+	indexes := make([]int, len(rows))
+	for i := range rows {
+		indexes[i] = i
+	}
+	if order == entryOrderSeat {
+		slices.SortStableFunc(indexes, func(a, b int) int {
+			return seatCompare(cellText(e.g.loadedTable, a, studentColSeat), cellText(e.g.loadedTable, b, studentColSeat))
+		})
+	}
+	e.order = make([]string, len(indexes))
+	for i, row := range indexes {
+		e.order[i] = strings.TrimSpace(cellText(e.g.loadedTable, row, studentColMatNr))
+	}
+	e.index = max(slices.Index(e.order, current), 0)
+	e.g.preferences().SetString(prefEntryOrder, order)
+	e.show()
+}
+
+// show fills the entries with the task points typed in before, as long as
+// they still add up to the points of the student.
+func (e *pointEntry) show() {
+	if len(e.order) == 0 {
+		return
+	}
+	matNr := e.order[e.index]
+	row := e.g.studentRow(matNr)
+	points, _ := cellValueAt(e.g.loadedTable, row, studentColPoints).(float64)
+	e.student.SetText(fmt.Sprintf("%d of %d   Seat %s   %s (%s)", e.index+1, len(e.order),
+		cellText(e.g.loadedTable, row, studentColSeat), cellText(e.g.loadedTable, row, studentColName), matNr))
+
+	stored := e.g.taskPoints[matNr]
+	corrected := e.g.correctedError(matNr)
+	for i, entry := range e.entries {
+		if corrected != nil {
+			entry.Disable()
+		} else {
+			entry.Enable()
+		}
+		switch {
+		case len(stored) == len(e.entries) && sumPoints(stored) == points:
+			entry.SetText(strconv.FormatFloat(stored[i], 'f', -1, 64))
+		case len(e.entries) == 1:
+			entry.SetText(strconv.FormatFloat(points, 'f', -1, 64))
+		default:
+			entry.SetText("")
+		}
+	}
+	e.updateTotal()
+	if corrected != nil {
+		e.message.SetText(corrected.Error() + ", click the points in the graded students table to log a correction")
+	}
+}
+
+// values parses the task entries; empty entries count as 0 and filled is
+// false when all are empty.
+func (e *pointEntry) values() ([]float64, bool, error) {
+	values := make([]float64, len(e.entries))
+	filled := false
+	for i, entry := range e.entries {
+		text := strings.TrimSpace(entry.Text)
+		if text == "" {
+			continue
+		}
+		filled = true
+		v, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64)
+		if err != nil {
+			return nil, true, fmt.Errorf("%s: points must be a number, e.g. 4.5", e.tasks[i].Name())
+		}
+		if v < 0 || v > e.tasks[i].Max() {
+			return nil, true, fmt.Errorf("%s: points must be between 0 and %.1f", e.tasks[i].Name(), e.tasks[i].Max())
+		}
+		values[i] = v
+	}
+	if total := sumPoints(values); total > e.g.pMax {
+		return nil, true, fmt.Errorf("total %.1f is above the max points %.1f", total, e.g.pMax)
+	}
+	return values, filled, nil
+}
+
+// updateTotal shows the running total and the grade it gives.
+func (e *pointEntry) updateTotal() {
+	if len(e.order) == 0 || e.g.gradeFor == nil {
+		return
+	}
+	matNr := e.order[e.index]
+	values, filled, err := e.values()
+	if err != nil {
+		e.total.SetText("Total -")
+		e.message.SetText(err.Error())
+		return
+	}
+	e.message.SetText("")
+	if !filled {
+		points, _ := cellValueAt(e.g.loadedTable, e.g.studentRow(matNr), studentColPoints).(float64)
+		e.total.SetText(fmt.Sprintf("Current points %.1f of %.1f   Grade %.1f", points, e.g.pMax, e.g.gradeFor(matNr, points)))
+		return
+	}
+	total := sumPoints(values)
+	e.total.SetText(fmt.Sprintf("Total %.1f of %.1f   Grade %.1f", total, e.g.pMax, e.g.gradeFor(matNr, total)))
+}
+
+// commit stores the total of the current student as an undoable edit.
+func (e *pointEntry) commit() bool {
+	if len(e.order) == 0 || e.g.correctedError(e.order[e.index]) != nil {
+		return true
+	}
+	values, filled, err := e.values()
+	if err != nil {
+		e.message.SetText(err.Error())
+		return false
+	}
+	if !filled {
+		return true
+	}
+	matNr := e.order[e.index]
+	row := e.g.studentRow(matNr)
+	if row < 0 {
+		return true
+	}
+	if e.g.taskPoints == nil {
+		e.g.taskPoints = make(map[string][]float64)
+	}
+	e.g.taskPoints[matNr] = values
+
+	total := sumPoints(values)
+	old := cellValueAt(e.g.loadedTable, row, studentColPoints)
+	if points, ok := old.(float64); ok && points == total {
+		return true
+	}
+	if err := e.g.execute(setCellCommand{matNr: matNr, col: studentColPoints, old: old, new: total}); err != nil {
+		e.message.SetText(err.Error())
+		return false
+	}
+	e.g.statusLabel.SetText(fmt.Sprintf("Entered %.1f points for %s", total, matNr))
+	return true
+}
+
+func (e *pointEntry) submitted(task int) {
+	if task < len(e.entries)-1 {
+		e.focus(task + 1)
+		return
+	}
+	e.move(1)
+}
+
+func (e *pointEntry) move(delta int) {
+	if !e.commit() {
+		return
+	}
+	next := e.index + delta
+	if next < 0 || next >= len(e.order) {
+		e.show()
+		if next < 0 {
+			e.message.SetText("This is the first student")
+		} else {
+			e.message.SetText(fmt.Sprintf("This is the last of %d students", len(e.order)))
+		}
+		e.focus(0)
+		return
+	}
+	e.index = next
+	e.show()
+	e.focus(0)
+}
+
+// jumpTo goes to the student with the typed matriculation number, or to the
+// only one starting with it.
+func (e *pointEntry) jumpTo(text string) {
+	text = strings.TrimSpace(text)
+	index := slices.Index(e.order, text)
+	if index < 0 {
+		for i, matNr := range e.order {
+			if strings.HasPrefix(matNr, text) {
+				if index >= 0 {
+					e.message.SetText(fmt.Sprintf("more than one student starts with %s", text))
+					return
+				}
+				index = i
+			}
+		}
+	}
+	if text == "" || index < 0 {
+		e.message.SetText(fmt.Sprintf("no student with Mat-Nr %s", text))
+		return
+	}
+	if !e.commit() {
+		return
+	}
+	e.index = index
+	e.jump.SetText("")
+	e.show()
+	e.focus(0)
+}
+
+func (e *pointEntry) focus(task int) {
+	if task < len(e.entries) {
+		e.g.window.Canvas().Focus(e.entries[task])
+	}
+}
+
+func (e *pointEntry) close() {
+	if !e.commit() {
+		return
+	}
+	e.dialog.Hide()
+}
+
+// seatCompare sorts seats naturally, A2 before A10, and students without a
+// seat last.
+func seatCompare(a, b string) int {
+	a, b = strings.ToUpper(strings.TrimSpace(a)), strings.ToUpper(strings.TrimSpace(b))
+	if a == "" || b == "" {
+		return strings.Compare(b, a)
+	}
+	for a != "" && b != "" {
+		chunkA, restA := seatChunk(a)
+		chunkB, restB := seatChunk(b)
+		numA, errA := strconv.Atoi(chunkA)
+		numB, errB := strconv.Atoi(chunkB)
+		switch {
+		case errA == nil && errB == nil && numA != numB:
+			return numA - numB
+		case (errA != nil || errB != nil) && chunkA != chunkB:
+			return strings.Compare(chunkA, chunkB)
+		}
+		a, b = restA, restB
+	}
+	return len(a) - len(b)
+}
+
+// seatChunk splits off the leading run of digits or of other characters.
+func seatChunk(s string) (string, string) {
+	digit := unicode.IsDigit(rune(s[0]))
+	i := 1
+	for i < len(s) && unicode.IsDigit(rune(s[i])) == digit {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// sumPoints adds task points, rounded to hundredths so that e.g. 0.1 + 0.2
+// is stored as 0.3.
+func sumPoints(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return math.Round(100*total) / 100
+}
@@ -0,0 +1,133 @@
+package gui
+
+import (
+	"fmt"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// newSliders creates the sliders of the threshold explorer, which move pMax
+// and pPass and regrade the exam on every change.
+func (g *GUI) newSliders() {
+	g.maxPointsSlider = widget.NewSlider(1, sliderMaxPoints)
+	g.maxPointsSlider.Step = sliderStep
+	g.maxPointsSlider.OnChanged = func(value float64) {
+		g.sliderChanged(value, math.Min(g.pPass, value-sliderStep))
+	}
+	g.maxPointsSlider.OnChangeEnded = g.sliderChangeEnded
+
+	g.passPointsSlider = widget.NewSlider(0, sliderMaxPoints)
+	g.passPointsSlider.Step = sliderStep
+	g.passPointsSlider.OnChanged = func(value float64) {
+		g.sliderChanged(g.pMax, value)
+	}
+	g.passPointsSlider.OnChangeEnded = g.sliderChangeEnded
+}
+
+func (g *GUI) sliderChanged(maxVal, passVal float64) {
+	if g.syncingSliders {
+		return
+	}
+	if passVal >= maxVal {
+		passVal = maxVal - sliderStep
+	}
+	if passVal < 0 {
+		passVal = 0
+	}
+	if !g.sliderDragging {
+		g.sliderDragging = true
+		g.sliderFrom = settingsCommand{oldMax: g.pMax, oldPass: g.pPass}
+	}
+
+	g.pMax = maxVal
+	g.pPass = passVal
+	g.setSettingsText()
+	if err := g.rebuildTables(); err != nil {
+		g.statusLabel.SetText(fmt.Sprintf("Rebuild failed: %v", err))
+		return
+	}
+	g.renderTables()
+	g.syncPassSlider()
+	g.statusLabel.SetText(fmt.Sprintf("max: %.1f, pass: %.1f", g.pMax, g.pPass))
+}
+
+// sliderChangeEnded records a whole slider drag as one undo step.
+func (g *GUI) sliderChangeEnded(float64) {
+	if g.syncingSliders || !g.sliderDragging {
+		return
+	}
+	g.sliderDragging = false
+	cmd := g.sliderFrom
+	cmd.newMax, cmd.newPass = g.pMax, g.pPass
+	if cmd.newMax != cmd.oldMax || cmd.newPass != cmd.oldPass {
+		g.record(cmd)
+	}
+}
+
+// syncPassSlider keeps the pass slider below the current max points while the
+// max slider is dragged.
+func (g *GUI) syncPassSlider() {
+	g.syncingSliders = true
+	defer func() { g.syncingSliders = false }()
+
+	g.passPointsSlider.Max = g.pMax
+	g.passPointsSlider.SetValue(g.pPass)
+	g.passPointsSlider.Refresh()
+}
+
+// syncSliders moves the sliders to the current settings without triggering a
+// regrade. The slider range grows with the points of the loaded students.
+func (g *GUI) syncSliders() {
+	g.syncingSliders = true
+	defer func() { g.syncingSliders = false }()
+
+	upper := math.Max(sliderMaxPoints, 2*g.pMax)
+	if g.statistics != nil {
+		upper = math.Max(upper, math.Ceil(g.maxStudentPoints))
+	}
+	g.maxPointsSlider.Max = upper
+	g.passPointsSlider.Max = g.pMax
+	g.maxPointsSlider.SetValue(g.pMax)
+	g.passPointsSlider.SetValue(g.pPass)
+	g.maxPointsSlider.Refresh()
+	g.passPointsSlider.Refresh()
+}
+
+func (g *GUI) buildSliders() fyne.CanvasObject {
+	size := fyne.NewSize(sliderWidth, g.maxPointsSlider.MinSize().Height)
+	return container.NewHBox(
+		widget.NewLabel("Max"),
+		container.NewGridWrap(size, g.maxPointsSlider),
+		widget.NewLabel("Pass"),
+		container.NewGridWrap(size, g.passPointsSlider),
+	)
+}
+
+func (g *GUI) buildSidePanel() fyne.CanvasObject {
+	summary := container.NewVBox(widget.NewLabelWithStyle("Pass Mark", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), g.passLabel)
+	stats := container.NewBorder(widget.NewLabel("Statistics"), nil, nil, nil, g.statsTable.Widget())
+	distribution := container.NewBorder(widget.NewLabel("Grade Distribution"), nil, nil, nil, g.distributionTable.Widget())
+	split := container.NewVSplit(stats, distribution)
+	split.Offset = 0.5
+	return container.NewBorder(summary, nil, nil, nil, split)
+}
+
+func (g *GUI) renderSidePanel(width float32) {
+	if g.statistics == nil || g.distribution == nil {
+		g.statsTable.clear()
+		g.distributionTable.clear()
+		g.passLabel.SetText("No students loaded")
+		return
+	}
+
+	g.passLabel.SetText(g.passSummary)
+	g.statsTable.setData(g.statistics, formatPlainCell)
+	g.distributionTable.setData(g.distribution, formatDistributionCell)
+	g.statsTable.resizeToFit(width)
+	g.distributionTable.resizeToFit(width)
+	g.statsTable.Widget().Refresh()
+	g.distributionTable.Widget().Refresh()
+}
@@ -0,0 +1,96 @@
+package gui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+const (
+	filterAll      = "All students"
+	filterFailed   = "Failed"
+	filterPassed   = "Passed"
+	filterNearPass = "Near pass mark"
+)
+
+func (g *GUI) newFilters() {
+	g.searchEntry = widget.NewEntry()
+	g.searchEntry.SetPlaceHolder("Search name, mat or seat")
+	g.searchEntry.OnChanged = func(string) { g.updateFilter() }
+
+	g.quickFilter = widget.NewSelect([]string{filterAll, filterFailed, filterPassed, filterNearPass}, func(string) { g.updateFilter() })
+	g.quickFilter.SetSelected(filterAll)
+}
+
+func (g *GUI) buildFilterBar() fyne.CanvasObject {
+	return container.NewBorder(nil, nil, nil, g.quickFilter, g.searchEntry)
+}
+
+// updateFilter applies search text and quick filter to the graded students.
+// The filter reads the current pass points, so it follows setting changes.
+func (g *GUI) updateFilter() {
+	if g.searchEntry == nil || g.quickFilter == nil {
+		return
+	}
+	search := strings.ToLower(strings.TrimSpace(g.searchEntry.Text))
+	quick := g.quickFilter.Selected
+	if search == "" && (quick == "" || quick == filterAll) {
+		g.gradedTable.setFilter(nil)
+		g.showFilterStatus()
+		return
+	}
+
+	g.gradedTable.setFilter(func(row utilities.TableRow) bool {
+		return g.matchesSearch(row, search) && g.matchesQuickFilter(row, quick)
+	})
+	g.showFilterStatus()
+}
+
+func (g *GUI) matchesSearch(row utilities.TableRow, search string) bool {
+	if search == "" {
+		return true
+	}
+	for _, header := range []string{"Student Name", "Mat", "Seat"} {
+		value := textValue(g.gradedCell(row, header))
+		if strings.Contains(strings.ToLower(value), search) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *GUI) matchesQuickFilter(row utilities.TableRow, quick string) bool {
+	grade, okGrade := numericValue(g.gradedCell(row, "Grade"))
+	points, okPoints := numericValue(g.gradedCell(row, "Points"))
+	switch quick {
+	case filterFailed:
+		return okGrade && grade > 4.0
+	case filterPassed:
+		return okGrade && grade <= 4.0
+	case filterNearPass:
+		return okPoints && math.Abs(points-g.pPass) <= nearPassPoints
+	}
+	return true
+}
+
+func (g *GUI) gradedCell(row utilities.TableRow, header string) any {
+	if g.gradedStudents == nil {
+		return nil
+	}
+	return cellAt(row, g.gradedStudents.ColumnIndex(header))
+}
+
+func (g *GUI) showFilterStatus() {
+	if g.gradedStudents == nil {
+		return
+	}
+	total := len(g.gradedStudents.Rows())
+	if shown := g.gradedTable.visibleRows(); shown < total {
+		g.statusLabel.SetText(fmt.Sprintf("Showing %d of %d students", shown, total))
+	}
+}
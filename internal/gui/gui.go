@@ -2,59 +2,115 @@ package gui
 
 import (
 	"fmt"
-	"strings"
+	"io"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
+	"github.com/andreaswillibaldweber/gogrades/internal/project"
 	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
 )
 
+// GUI is one exam, shown in its own tab of the workspace window.
 type GUI struct {
-	window fyne.Window
+	window  fyne.Window
+	ws      *workspace
+	tab     *container.TabItem
+	id      int
+	content fyne.CanvasObject
 
 	pMax  float64
 	pPass float64
 
-	maxPointsEntry  *widget.Entry
-	passPointsEntry *widget.Entry
-	statusLabel     *widget.Label
-
-	loadedCSVPath string
-	loadedTable   *utilities.Table
-
-	gradedStudents *utilities.Table
-	gradingKey     *utilities.Table
-
-	gradedTable *tableAdapter
-	keyTable    *tableAdapter
+	maxPointsEntry   *widget.Entry
+	passPointsEntry  *widget.Entry
+	maxPointsSlider  *widget.Slider
+	passPointsSlider *widget.Slider
+	syncingSliders   bool
+	sliderDragging   bool
+	sliderFrom       settingsCommand
+	statusLabel      *widget.Label
+	passLabel        *widget.Label
+	searchEntry      *widget.Entry
+	quickFilter      *widget.Select
+
+	project project.Project
+
+	loadedCSVPath    string
+	loadedTable      *utilities.Table
+	selectedMatNr    string
+	dirty            bool
+	bonusTable       *utilities.Table
+	correctionsTable *utilities.Table
+
+	gradedStudents    *utilities.Table
+	gradingKey        *utilities.Table
+	statistics        *utilities.Table
+	distribution      *utilities.Table
+	passSummary       string
+	maxStudentPoints  float64
+	rowLevels         []string
+	rowTooltips       []string
+	hoverReason       string
+	statusBeforeHover string
+	gradeFor          func(matNr string, points float64) float64
+	writeHTMLReport   func(w io.Writer) error
+	writePDFReport    func(w io.Writer, signature bool) error
+	logCorrection     func(matNr string, points float64, reason, examiner string) error
+	taskPoints        map[string][]float64
+
+	gradedTable       *tableAdapter
+	keyTable          *tableAdapter
+	statsTable        *tableAdapter
+	distributionTable *tableAdapter
+
+	charts    []*chart
+	chartData chartData
+
+	history history
+
+	tablesSplit *container.Split
+	chartSplit  *container.Split
+	sideSplit   *container.Split
 }
 
-func newGUI(pMax, pPass float64) *GUI {
-	a := app.NewWithID("gogrades")
-	w := a.NewWindow("GoGrades")
-	w.Resize(fyne.NewSize(windowWidth, windowHeight))
-
+func newGUI(ws *workspace, proj project.Project, id int) *GUI {
 	g := &GUI{
-		window:          w,
-		pMax:            pMax,
-		pPass:           pPass,
+		window:          ws.window,
+		ws:              ws,
+		id:              id,
+		project:         proj,
+		pMax:            proj.PMax(),
+		pPass:           proj.PPass(),
 		maxPointsEntry:  widget.NewEntry(),
 		passPointsEntry: widget.NewEntry(),
 		statusLabel:     widget.NewLabel("No CSV loaded. Use File -> Open CSV..."),
+		passLabel:       widget.NewLabel(""),
 		gradedTable: newTableAdapter(func(colIdx int) bool {
 			return colIdx == 3 || colIdx == 4 || colIdx == 5
 		}),
 		keyTable: newTableAdapter(func(colIdx int) bool {
 			return colIdx == 1 || colIdx == 2 || colIdx == 3
 		}),
+		statsTable: newTableAdapter(func(colIdx int) bool {
+			return colIdx == 1
+		}),
+		distributionTable: newTableAdapter(func(colIdx int) bool {
+			return true
+		}),
 	}
 
-	g.maxPointsEntry.SetText(fmt.Sprintf("%.1f", pMax))
-	g.passPointsEntry.SetText(fmt.Sprintf("%.1f", pPass))
-	g.window.SetMainMenu(g.buildMainMenu())
-	g.window.SetContent(g.buildContent())
+	g.gradedTable.onSelected = g.gradedCellSelected
+	g.gradedTable.sortable = true
+	g.gradedTable.rowStyle = g.gradedRowStyle
+	g.gradedTable.onHover = g.showHighlightReason
+	g.newFilters()
+	g.newSliders()
+	g.newCharts()
+	g.setSettingsText()
+	g.syncSliders()
+	g.content = g.buildContent()
+	g.restoreLayout()
 	return g
 }
 
@@ -65,31 +121,50 @@ func (g *GUI) buildControls() *fyne.Container {
 		widget.NewLabel("Pass Points"),
 		container.NewGridWrap(fyne.NewSize(90, g.passPointsEntry.MinSize().Height), g.passPointsEntry),
 		widget.NewButton("Apply", g.applySettings),
+		g.buildSliders(),
 		g.statusLabel,
 	)
 }
 
 func (g *GUI) buildContent() fyne.CanvasObject {
-	leftPane := container.NewBorder(widget.NewLabel("Graded Students"), nil, nil, nil, g.gradedTable.Widget())
+	leftPane := container.NewBorder(g.buildStudentHeader(), nil, nil, nil, g.gradedTable.Widget())
 	rightPane := container.NewBorder(widget.NewLabel("Grading Key"), nil, nil, nil, g.keyTable.Widget())
-	split := container.NewHSplit(leftPane, rightPane)
-	split.Offset = splitOffset
-	return container.NewBorder(g.buildControls(), nil, nil, nil, split)
+	g.tablesSplit = container.NewHSplit(leftPane, rightPane)
+	g.tablesSplit.Offset = splitOffset
+	g.chartSplit = container.NewVSplit(g.tablesSplit, g.buildCharts())
+	g.chartSplit.Offset = chartOffset
+	g.sideSplit = container.NewHSplit(g.chartSplit, g.buildSidePanel())
+	g.sideSplit.Offset = sideOffset
+	return container.NewBorder(g.buildControls(), nil, nil, nil, g.sideSplit)
 }
 
-func ShowExamTables(pMax, pPass float64, preloadCSVPath string) error {
-	g := newGUI(pMax, pPass)
+func (g *GUI) setSettingsText() {
+	g.maxPointsEntry.SetText(fmt.Sprintf("%.1f", g.pMax))
+	g.passPointsEntry.SetText(fmt.Sprintf("%.1f", g.pPass))
+}
 
-	if strings.TrimSpace(preloadCSVPath) != "" {
-		if err := g.loadCSVPath(preloadCSVPath); err != nil {
-			return err
-		}
+// ShowExamTables opens the GUI with proj in the first tab. With savedSettings
+// the points and grading settings of the last session replace those of proj;
+// the caller passes false when they were given explicitly.
+func ShowExamTables(proj project.Project, savedSettings bool) error {
+	ws := newWorkspace()
+	g := ws.addExam(proj)
+	if savedSettings {
+		g.restoreSettings()
+		g.setSettingsText()
+		g.syncSliders()
+	}
+
+	if err := g.loadProjectFiles(); err != nil {
+		return err
 	}
 
 	if err := g.rebuildTables(); err != nil {
 		return err
 	}
 	g.renderTables()
-	g.window.ShowAndRun()
+	ws.offerRecovery()
+	ws.startAutosave()
+	ws.window.ShowAndRun()
 	return nil
 }
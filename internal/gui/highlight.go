@@ -0,0 +1,224 @@
+package gui
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/andreaswillibaldweber/gogrades/internal/grades"
+	"github.com/andreaswillibaldweber/gogrades/internal/project"
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+// tableCell is a table label with a background colour. While the mouse is
+// over the cell, onHover gets the reason of the highlight, and "" when the
+// mouse leaves. A popup would take the hit-testing away from the table.
+type tableCell struct {
+	widget.BaseWidget
+	background *canvas.Rectangle
+	label      *widget.Label
+	reason     string
+	hovered    bool
+	onHover    func(reason string)
+}
+
+func newTableCell(onHover func(reason string)) *tableCell {
+	c := &tableCell{
+		background: canvas.NewRectangle(color.Transparent),
+		label:      widget.NewLabel(""),
+		onHover:    onHover,
+	}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+func (c *tableCell) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewStack(c.background, c.label))
+}
+
+// setStyle is called for every cell update; cells are reused while
+// scrolling, so a hovered cell reports its new reason.
+func (c *tableCell) setStyle(background color.Color, reason string) {
+	if background == nil {
+		background = color.Transparent
+	}
+	if c.background.FillColor != background {
+		c.background.FillColor = background
+		c.background.Refresh()
+	}
+	if c.reason != reason {
+		c.reason = reason
+		if c.hovered {
+			c.hover(reason)
+		}
+	}
+}
+
+func (c *tableCell) hover(reason string) {
+	if c.onHover != nil {
+		c.onHover(reason)
+	}
+}
+
+func (c *tableCell) MouseIn(*desktop.MouseEvent) {
+	c.hovered = true
+	c.hover(c.reason)
+}
+
+func (c *tableCell) MouseMoved(*desktop.MouseEvent) {}
+
+func (c *tableCell) MouseOut() {
+	c.hovered = false
+	c.hover("")
+}
+
+// showHighlightReason shows why the row under the mouse is highlighted in
+// the status line and brings back the previous status when it leaves.
+func (g *GUI) showHighlightReason(reason string) {
+	switch {
+	case reason != "" && g.hoverReason == "":
+		g.statusBeforeHover = g.statusLabel.Text
+	case reason == "" && g.hoverReason != "" && g.statusLabel.Text == g.hoverReason:
+		g.statusLabel.SetText(g.statusBeforeHover)
+	}
+	g.hoverReason = reason
+	if reason != "" {
+		g.statusLabel.SetText(reason)
+	}
+}
+
+// highlightRules are the rules offered in the View menu.
+var highlightRules = []struct {
+	rule  string
+	label string
+}{
+	{grades.HighlightFailed, "Highlight Failed"},
+	{grades.HighlightTop, "Highlight Top Grades"},
+	{grades.HighlightAboveMax, "Warn Points Above Max"},
+	{grades.HighlightDuplicate, "Warn Duplicate Mat-Nr"},
+	{grades.HighlightMissingSeat, "Warn Missing Seat"},
+}
+
+func (ws *workspace) buildViewMenu() *fyne.Menu {
+	ws.highlightItems = make(map[string]*fyne.MenuItem)
+	items := make([]*fyne.MenuItem, 0, len(highlightRules))
+	for _, r := range highlightRules {
+		rule := r.rule
+		item := fyne.NewMenuItem(r.label, ws.on(func(g *GUI) { g.toggleHighlight(rule) }))
+		ws.highlightItems[rule] = item
+		items = append(items, item)
+	}
+	items = append(items,
+		fyne.NewMenuItemSeparator(),
+		ws.buildThemeMenu(),
+		fyne.NewMenuItem("Reset Column Widths", ws.on((*GUI).resetColumnWidths)),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Comparison", ws.showComparison),
+	)
+	return fyne.NewMenu("View", items...)
+}
+
+// updateViewMenu checks the highlight rules of the active exam.
+func (ws *workspace) updateViewMenu() {
+	if ws.current == nil {
+		return
+	}
+	for rule, item := range ws.highlightItems {
+		item.Checked = slices.Contains(ws.current.project.Highlights(), rule)
+	}
+	if menu := ws.window.MainMenu(); menu != nil {
+		menu.Refresh()
+	}
+}
+
+// toggleHighlight switches one rule and stores the rules in the project, so
+// they are saved with it and used by the HTML report.
+func (g *GUI) toggleHighlight(rule string) {
+	enabled := g.project.Highlights()
+	rules := make([]string, 0, len(grades.DefaultHighlights))
+	for _, r := range grades.DefaultHighlights {
+		if slices.Contains(enabled, r) != (r == rule) {
+			rules = append(rules, r)
+		}
+	}
+	g.project.SetHighlights(rules, g.project.TopGrade())
+	if err := g.rebuildTables(); err != nil {
+		dialog.ShowError(fmt.Errorf("rebuild tables: %w", err), g.window)
+		return
+	}
+	g.renderTables()
+	g.ws.updateViewMenu()
+}
+
+// gradedRowStyle colours a row of the graded students table by its highlight
+// level.
+func (g *GUI) gradedRowStyle(row int) (color.Color, string) {
+	if row < 0 || row >= len(g.rowLevels) {
+		return nil, ""
+	}
+	var name fyne.ThemeColorName
+	switch g.rowLevels[row] {
+	case grades.LevelFailed:
+		name = theme.ColorNameError
+	case grades.LevelTop:
+		name = theme.ColorNameSuccess
+	case grades.LevelWarning:
+		name = theme.ColorNameWarning
+	default:
+		return nil, g.rowTooltips[row]
+	}
+	return withAlpha(theme.Color(name), highlightAlpha), g.rowTooltips[row]
+}
+
+func withAlpha(c color.Color, alpha uint8) color.Color {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	nrgba.A = alpha
+	return nrgba
+}
+
+func (g *GUI) exportHTML() {
+	if g.gradedStudents == nil || g.gradingKey == nil {
+		dialog.ShowError(fmt.Errorf("no data loaded to export"), g.window)
+		return
+	}
+	if strings.TrimSpace(g.loadedCSVPath) == "" {
+		dialog.ShowError(fmt.Errorf("missing source CSV path for predefined save names"), g.window)
+		return
+	}
+
+	path := g.project.OutputPathExt(project.OutputReport, ".html")
+	if len(utilities.ExistingFiles([]string{path})) == 0 {
+		g.writeHTML(path)
+		return
+	}
+	dialog.ShowConfirm("Overwrite file", fmt.Sprintf("Overwrite existing file?\n%s", path), func(ok bool) {
+		if ok {
+			g.writeHTML(path)
+		}
+	}, g.window)
+}
+
+func (g *GUI) writeHTML(path string) {
+	if err := g.writeHTMLFile(path); err != nil {
+		dialog.ShowError(fmt.Errorf("save HTML report: %w", err), g.window)
+		return
+	}
+	g.statusLabel.SetText(fmt.Sprintf("Saved %s", path))
+}
+
+func (g *GUI) writeHTMLFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create output folder: %w", err)
+	}
+	return utilities.WriteFileAtomic(path, g.writeHTMLReport)
+}
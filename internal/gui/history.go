@@ -0,0 +1,159 @@
+package gui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2/dialog"
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+// editCommand is one undoable change. do and undo are applied to the GUI and
+// regrade the exam like a direct edit.
+type editCommand interface {
+	do(g *GUI) error
+	undo(g *GUI) error
+	String() string
+}
+
+type history struct {
+	done   []editCommand
+	undone []editCommand
+}
+
+func (h *history) clear() {
+	h.done = nil
+	h.undone = nil
+}
+
+func (h *history) push(cmd editCommand) {
+	h.done = append(h.done, cmd)
+	h.undone = nil
+}
+
+func (h history) canUndo() bool {
+	return len(h.done) > 0
+}
+
+func (h history) canRedo() bool {
+	return len(h.undone) > 0
+}
+
+// execute applies a command and records it for undo.
+func (g *GUI) execute(cmd editCommand) error {
+	if err := cmd.do(g); err != nil {
+		return err
+	}
+	g.history.push(cmd)
+	g.updateEditMenu()
+	return nil
+}
+
+// record adds a command that has already been applied, e.g. a slider drag.
+func (g *GUI) record(cmd editCommand) {
+	g.history.push(cmd)
+	g.updateEditMenu()
+}
+
+func (g *GUI) undo() {
+	if !g.history.canUndo() {
+		return
+	}
+	cmd := g.history.done[len(g.history.done)-1]
+	if err := cmd.undo(g); err != nil {
+		dialog.ShowError(fmt.Errorf("undo %s: %w", cmd, err), g.window)
+		return
+	}
+	g.history.done = g.history.done[:len(g.history.done)-1]
+	g.history.undone = append(g.history.undone, cmd)
+	g.updateEditMenu()
+	g.statusLabel.SetText(fmt.Sprintf("Undo: %s", cmd))
+}
+
+func (g *GUI) redo() {
+	if !g.history.canRedo() {
+		return
+	}
+	cmd := g.history.undone[len(g.history.undone)-1]
+	if err := cmd.do(g); err != nil {
+		dialog.ShowError(fmt.Errorf("redo %s: %w", cmd, err), g.window)
+		return
+	}
+	g.history.undone = g.history.undone[:len(g.history.undone)-1]
+	g.history.done = append(g.history.done, cmd)
+	g.updateEditMenu()
+	g.statusLabel.SetText(fmt.Sprintf("Redo: %s", cmd))
+}
+
+func (g *GUI) clearHistory() {
+	g.history.clear()
+	g.updateEditMenu()
+}
+
+type setCellCommand struct {
+	matNr    string
+	col      int
+	old, new any
+}
+
+func (c setCellCommand) do(g *GUI) error {
+	return g.setStudentCell(g.studentRow(c.matNr), c.col, c.new)
+}
+
+func (c setCellCommand) undo(g *GUI) error {
+	return g.setStudentCell(g.studentRow(c.matNr), c.col, c.old)
+}
+
+func (c setCellCommand) String() string {
+	return fmt.Sprintf("edit %s of %s", studentHeaders[c.col], c.matNr)
+}
+
+type addRowCommand struct {
+	index int
+	row   utilities.TableRow
+}
+
+func (c addRowCommand) do(g *GUI) error {
+	return g.addStudentRow(c.index, c.row)
+}
+
+func (c addRowCommand) undo(g *GUI) error {
+	return g.deleteStudentRow(c.index)
+}
+
+func (c addRowCommand) String() string {
+	return fmt.Sprintf("add %v", c.row[studentColMatNr])
+}
+
+type deleteRowCommand struct {
+	index int
+	row   utilities.TableRow
+}
+
+func (c deleteRowCommand) do(g *GUI) error {
+	return g.deleteStudentRow(c.index)
+}
+
+func (c deleteRowCommand) undo(g *GUI) error {
+	return g.addStudentRow(c.index, c.row)
+}
+
+func (c deleteRowCommand) String() string {
+	return fmt.Sprintf("delete %v", c.row[studentColMatNr])
+}
+
+type settingsCommand struct {
+	oldMax, oldPass float64
+	newMax, newPass float64
+}
+
+func (c settingsCommand) do(g *GUI) error {
+	return g.setPoints(c.newMax, c.newPass)
+}
+
+func (c settingsCommand) undo(g *GUI) error {
+	return g.setPoints(c.oldMax, c.oldPass)
+}
+
+func (c settingsCommand) String() string {
+	return fmt.Sprintf("max/pass %.1f/%.1f", c.newMax, c.newPass)
+}
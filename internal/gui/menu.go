@@ -2,11 +2,53 @@ package gui
 
 import "fyne.io/fyne/v2"
 
-func (g *GUI) buildMainMenu() *fyne.MainMenu {
+// on runs action on the exam of the active tab.
+func (ws *workspace) on(action func(*GUI)) func() {
+	return func() {
+		if ws.current != nil {
+			action(ws.current)
+		}
+	}
+}
+
+func (ws *workspace) buildMainMenu() *fyne.MainMenu {
 	fileMenu := fyne.NewMenu("File",
-		fyne.NewMenuItem("Open CSV...", g.openCSVDialog),
-		fyne.NewMenuItem("Save CSV...", g.saveCSV),
+		fyne.NewMenuItem("New Exam Tab", ws.newExamTab),
+		fyne.NewMenuItem("Open CSV...", ws.on((*GUI).openCSVDialog)),
+		fyne.NewMenuItem("Open Project...", ws.on((*GUI).openProjectDialog)),
+		ws.buildRecentMenu(),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Save Students", ws.on((*GUI).saveStudents)),
+		fyne.NewMenuItem("Save As...", ws.on((*GUI).saveAsDialog)),
+		fyne.NewMenuItem("Save Results", ws.on((*GUI).saveResults)),
+		fyne.NewMenuItem("Save Project...", ws.on((*GUI).saveProjectDialog)),
+		fyne.NewMenuItem("Export HTML Report", ws.on((*GUI).exportHTML)),
+		fyne.NewMenuItem("Print...", ws.on((*GUI).printDialog)),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Close Tab", ws.closeCurrentTab),
+		fyne.NewMenuItemSeparator(),
+	)
+
+	ws.undoItem = fyne.NewMenuItem("Undo", ws.on((*GUI).undo))
+	ws.undoItem.Shortcut = &fyne.ShortcutUndo{}
+	ws.redoItem = fyne.NewMenuItem("Redo", ws.on((*GUI).redo))
+	ws.redoItem.Shortcut = &fyne.ShortcutRedo{}
+	editMenu := fyne.NewMenu("Edit", ws.undoItem, ws.redoItem,
 		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Point Entry...", ws.on((*GUI).pointEntryDialog)),
 	)
-	return fyne.NewMainMenu(fileMenu)
+
+	return fyne.NewMainMenu(fileMenu, editMenu, ws.buildViewMenu())
+}
+
+func (g *GUI) updateEditMenu() {
+	ws := g.ws
+	if !g.isActive() || ws.undoItem == nil || ws.redoItem == nil {
+		return
+	}
+	ws.undoItem.Disabled = !g.history.canUndo()
+	ws.redoItem.Disabled = !g.history.canRedo()
+	if menu := g.window.MainMenu(); menu != nil {
+		menu.Refresh()
+	}
 }
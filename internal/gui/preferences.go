@@ -0,0 +1,183 @@
+package gui
+
+import (
+	"image/color"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+)
+
+// Preference keys of the settings and the window state. They are stored by
+// Fyne under the app ID "gogrades".
+const (
+	prefPMax          = "pMax"
+	prefPPass         = "pPass"
+	prefStep          = "step"
+	prefRounding      = "rounding"
+	prefGranularity   = "granularity"
+	prefWindowWidth   = "windowWidth"
+	prefWindowHeight  = "windowHeight"
+	prefSplitOffset   = "splitOffset"
+	prefChartOffset   = "chartOffset"
+	prefSideOffset    = "sideOffset"
+	prefGradedColumns = "gradedColumnWidths"
+	prefKeyColumns    = "keyColumnWidths"
+	prefLastDirectory = "lastDirectory"
+	prefTheme         = "theme"
+	prefEntryOrder    = "entryOrder"
+)
+
+const (
+	themeSystem = "system"
+	themeLight  = "light"
+	themeDark   = "dark"
+)
+
+// restoreSettings replaces the points and grading settings of the project by
+// those of the last session.
+func (g *GUI) restoreSettings() {
+	prefs := g.preferences()
+	pMax := prefs.FloatWithFallback(prefPMax, g.pMax)
+	pPass := prefs.FloatWithFallback(prefPPass, g.pPass)
+	if pMax > 0 && pPass >= 0 && pPass < pMax {
+		g.pMax, g.pPass = pMax, pPass
+	}
+	step, rounding, granularity := g.project.Step(), g.project.Rounding(), g.project.Granularity()
+	g.project.SetRounding(
+		prefs.StringWithFallback(prefStep, step),
+		prefs.StringWithFallback(prefRounding, rounding),
+		prefs.FloatWithFallback(prefGranularity, granularity),
+	)
+	if err := g.project.Validate(); err != nil {
+		g.project.SetRounding(step, rounding, granularity)
+	}
+}
+
+// restoreWindowState applies the window size and theme of the last session.
+func (ws *workspace) restoreWindowState() {
+	prefs := ws.preferences()
+	ws.window.Resize(fyne.NewSize(
+		float32(prefs.FloatWithFallback(prefWindowWidth, windowWidth)),
+		float32(prefs.FloatWithFallback(prefWindowHeight, windowHeight)),
+	))
+	ws.setTheme(prefs.StringWithFallback(prefTheme, themeSystem))
+}
+
+// restoreLayout applies the split offsets and column widths of the last
+// session to a new exam tab.
+func (g *GUI) restoreLayout() {
+	prefs := g.preferences()
+	g.tablesSplit.Offset = validOffset(prefs.FloatWithFallback(prefSplitOffset, splitOffset), splitOffset)
+	g.chartSplit.Offset = validOffset(prefs.FloatWithFallback(prefChartOffset, chartOffset), chartOffset)
+	g.sideSplit.Offset = validOffset(prefs.FloatWithFallback(prefSideOffset, sideOffset), sideOffset)
+	g.gradedTable.setUserWidths(prefs.FloatList(prefGradedColumns))
+	g.keyTable.setUserWidths(prefs.FloatList(prefKeyColumns))
+}
+
+func validOffset(offset, fallback float64) float64 {
+	if offset <= 0 || offset >= 1 {
+		return fallback
+	}
+	return offset
+}
+
+// saveState stores the window state and the settings and layout of the
+// active exam for the next session.
+func (ws *workspace) saveState() {
+	prefs := ws.preferences()
+	size := ws.window.Canvas().Size()
+	if size.Width > 0 && size.Height > 0 {
+		prefs.SetFloat(prefWindowWidth, float64(size.Width))
+		prefs.SetFloat(prefWindowHeight, float64(size.Height))
+	}
+	if ws.current != nil {
+		ws.current.saveState()
+	}
+}
+
+func (g *GUI) saveState() {
+	prefs := g.preferences()
+	prefs.SetFloat(prefPMax, g.pMax)
+	prefs.SetFloat(prefPPass, g.pPass)
+	prefs.SetString(prefStep, g.project.Step())
+	prefs.SetString(prefRounding, g.project.Rounding())
+	prefs.SetFloat(prefGranularity, g.project.Granularity())
+	prefs.SetFloat(prefSplitOffset, g.tablesSplit.Offset)
+	prefs.SetFloat(prefChartOffset, g.chartSplit.Offset)
+	prefs.SetFloat(prefSideOffset, g.sideSplit.Offset)
+	prefs.SetFloatList(prefGradedColumns, g.gradedTable.userWidthList())
+	prefs.SetFloatList(prefKeyColumns, g.keyTable.userWidthList())
+}
+
+// rememberDirectory keeps the folder of an opened or saved file as start
+// folder for the next file dialog.
+func (g *GUI) rememberDirectory(path string) {
+	if abs, err := filepath.Abs(filepath.Dir(path)); err == nil {
+		g.preferences().SetString(prefLastDirectory, abs)
+	}
+}
+
+func (g *GUI) lastDirectory() fyne.ListableURI {
+	dir := g.preferences().String(prefLastDirectory)
+	if strings.TrimSpace(dir) == "" {
+		return nil
+	}
+	location, err := storage.ListerForURI(storage.NewFileURI(dir))
+	if err != nil {
+		return nil
+	}
+	return location
+}
+
+// variantTheme is the default theme with a fixed light or dark variant.
+type variantTheme struct {
+	fyne.Theme
+	variant fyne.ThemeVariant
+}
+
+func (t variantTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	return t.Theme.Color(name, t.variant)
+}
+
+func (ws *workspace) setTheme(name string) {
+	switch name {
+	case themeLight:
+		fyne.CurrentApp().Settings().SetTheme(variantTheme{Theme: theme.DefaultTheme(), variant: theme.VariantLight})
+	case themeDark:
+		fyne.CurrentApp().Settings().SetTheme(variantTheme{Theme: theme.DefaultTheme(), variant: theme.VariantDark})
+	default:
+		name = themeSystem
+		fyne.CurrentApp().Settings().SetTheme(theme.DefaultTheme())
+	}
+	ws.preferences().SetString(prefTheme, name)
+	for itemName, item := range ws.themeItems {
+		item.Checked = itemName == name
+	}
+	if menu := ws.window.MainMenu(); menu != nil {
+		menu.Refresh()
+	}
+}
+
+func (ws *workspace) buildThemeMenu() *fyne.MenuItem {
+	ws.themeItems = make(map[string]*fyne.MenuItem)
+	items := make([]*fyne.MenuItem, 0, 3)
+	for _, t := range []struct{ name, label string }{{themeSystem, "System"}, {themeLight, "Light"}, {themeDark, "Dark"}} {
+		name := t.name
+		item := fyne.NewMenuItem(t.label, func() { ws.setTheme(name) })
+		item.Checked = name == ws.preferences().StringWithFallback(prefTheme, themeSystem)
+		ws.themeItems[name] = item
+		items = append(items, item)
+	}
+	themeItem := fyne.NewMenuItem("Theme", nil)
+	themeItem.ChildMenu = fyne.NewMenu("", items...)
+	return themeItem
+}
+
+func (g *GUI) resetColumnWidths() {
+	g.gradedTable.setUserWidths(nil)
+	g.keyTable.setUserWidths(nil)
+	g.renderTables()
+}
@@ -0,0 +1,41 @@
+package gui
+
+import (
+	"errors"
+	"io"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/andreaswillibaldweber/gogrades/internal/project"
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+// printDialog lays out the grading key and the graded students on A4 pages
+// and saves them as PDF, which any viewer can send to a printer.
+func (g *GUI) printDialog() {
+	if g.gradedStudents == nil || g.gradingKey == nil {
+		dialog.ShowError(errors.New("no data loaded to print"), g.window)
+		return
+	}
+	signature := widget.NewCheck("Signature lines for the examiners", nil)
+	items := []*widget.FormItem{widget.NewFormItem("Footer", signature)}
+	dialog.ShowForm("Print", "Choose File...", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		g.saveFileDialog(saveFormat{
+			label: "Grade list (PDF)",
+			kind:  project.OutputPrint,
+			ext:   ".pdf",
+			write: func(g *GUI, path string) error {
+				return g.writePDFFile(path, signature.Checked)
+			},
+		})
+	}, g.window)
+}
+
+func (g *GUI) writePDFFile(path string, signature bool) error {
+	return utilities.WriteFileAtomic(path, func(w io.Writer) error {
+		return g.writePDFReport(w, signature)
+	})
+}
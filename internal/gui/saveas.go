@@ -0,0 +1,215 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"github.com/andreaswillibaldweber/gogrades/internal/project"
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+// saveFormat is one choice of the Save As dialog. kind names the output for
+// the suggested file name, write saves to the chosen path.
+type saveFormat struct {
+	label string
+	kind  string
+	ext   string
+	write func(g *GUI, path string) error
+}
+
+var saveFormats = []saveFormat{
+	{"Graded students (CSV)", project.OutputGraded, ".csv", func(g *GUI, path string) error {
+		return g.gradedStudents.ToCSV(path)
+	}},
+	{"Graded students (JSON)", project.OutputGraded, ".json", func(g *GUI, path string) error {
+		return writeJSONFile(path, *g.gradedStudents)
+	}},
+	{"Grading key (CSV)", project.OutputGradingKey, ".csv", func(g *GUI, path string) error {
+		return g.gradingKey.ToCSV(path)
+	}},
+	{"Grading key (JSON)", project.OutputGradingKey, ".json", func(g *GUI, path string) error {
+		return writeJSONFile(path, *g.gradingKey)
+	}},
+	{"Report (HTML)", project.OutputReport, ".html", (*GUI).writeHTMLFile},
+	{"Student list (CSV)", "", ".csv", (*GUI).saveStudentsAs},
+}
+
+func writeJSONFile(path string, table utilities.Table) error {
+	return utilities.WriteFileAtomic(path, func(w io.Writer) error {
+		return utilities.WriteJSONTo(w, table)
+	})
+}
+
+func saveFormatLabels() []string {
+	labels := make([]string, len(saveFormats))
+	for i, f := range saveFormats {
+		labels[i] = f.label
+	}
+	return labels
+}
+
+func findSaveFormat(label string) (saveFormat, bool) {
+	for _, f := range saveFormats {
+		if f.label == label {
+			return f, true
+		}
+	}
+	return saveFormat{}, false
+}
+
+// saveAsDialog asks for the format first and then for the file, so the file
+// dialog can suggest a matching name and extension.
+func (g *GUI) saveAsDialog() {
+	if g.gradedStudents == nil || g.gradingKey == nil {
+		dialog.ShowError(errors.New("no data loaded to save"), g.window)
+		return
+	}
+	formatSelect := widget.NewSelect(saveFormatLabels(), nil)
+	formatSelect.SetSelectedIndex(0)
+	items := []*widget.FormItem{widget.NewFormItem("Format", formatSelect)}
+	dialog.ShowForm("Save As", "Choose File...", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if format, found := findSaveFormat(formatSelect.Selected); found {
+			g.saveFileDialog(format)
+		}
+	}, g.window)
+}
+
+func (g *GUI) saveFileDialog(format saveFormat) {
+	g.savePathDialog("Save "+format.label, g.suggestedFileName(format), []string{format.ext}, func(path string) error {
+		if err := format.write(g, path); err != nil {
+			return fmt.Errorf("save %s: %w", format.label, err)
+		}
+		g.statusLabel.SetText(fmt.Sprintf("Saved %s", path))
+		return nil
+	})
+}
+
+// savePathDialog asks for a file name and a folder without opening the file.
+// Fyne's save dialog creates and truncates the chosen file before its
+// callback runs, so a failed write would leave an existing file empty; here
+// only save writes the file, atomically, after asking to overwrite it.
+func (g *GUI) savePathDialog(title, fileName string, exts []string, save func(path string) error) {
+	name := widget.NewEntry()
+	name.SetText(fileName)
+	name.Validator = func(text string) error {
+		if strings.TrimSpace(text) == "" {
+			return errors.New("enter a file name")
+		}
+		return nil
+	}
+
+	folder, _ := os.Getwd()
+	if location := g.dialogLocation(); location != nil {
+		folder = location.Path()
+	}
+	folderLabel := widget.NewLabel(folder)
+	folderLabel.Truncation = fyne.TextTruncateEllipsis
+	choose := widget.NewButton("Choose...", func() {
+		folderDialog := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("folder dialog: %w", err), g.window)
+				return
+			}
+			if uri != nil {
+				folder = uri.Path()
+				folderLabel.SetText(folder)
+			}
+		}, g.window)
+		if location, err := storage.ListerForURI(storage.NewFileURI(folder)); err == nil {
+			folderDialog.SetLocation(location)
+		}
+		folderDialog.Show()
+	})
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("File name", name),
+		widget.NewFormItem("Folder", container.NewBorder(nil, nil, nil, choose, folderLabel)),
+	}
+	form := dialog.NewForm(title, "Save", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		path := filepath.Join(folder, strings.TrimSpace(name.Text))
+		if !slices.Contains(exts, strings.ToLower(filepath.Ext(path))) {
+			path += exts[0]
+		}
+		write := func() {
+			if err := save(path); err != nil {
+				dialog.ShowError(err, g.window)
+				return
+			}
+			g.rememberDirectory(path)
+		}
+		info, err := os.Stat(path)
+		switch {
+		case err == nil && info.IsDir():
+			dialog.ShowError(fmt.Errorf("%s is a folder, choose another file name", path), g.window)
+		case err == nil:
+			dialog.ShowConfirm("Overwrite?", fmt.Sprintf("Overwrite the existing file\n%s?", path), func(ok bool) {
+				if ok {
+					write()
+				}
+			}, g.window)
+		default:
+			write()
+		}
+	}, g.window)
+	form.Resize(fyne.NewSize(saveDialogWidth, form.MinSize().Height))
+	form.Show()
+}
+
+func (g *GUI) suggestedFileName(format saveFormat) string {
+	if strings.TrimSpace(g.loadedCSVPath) == "" {
+		return "students" + format.ext
+	}
+	if format.kind == "" {
+		return filepath.Base(g.loadedCSVPath)
+	}
+	return filepath.Base(g.project.OutputPathExt(format.kind, format.ext))
+}
+
+// dialogLocation starts save dialogs in the folder of the loaded student file
+// or else in the last used folder.
+func (g *GUI) dialogLocation() fyne.ListableURI {
+	if strings.TrimSpace(g.loadedCSVPath) == "" {
+		return g.lastDirectory()
+	}
+	location, err := storage.ListerForURI(storage.NewFileURI(filepath.Dir(g.loadedCSVPath)))
+	if err != nil {
+		return nil
+	}
+	return location
+}
+
+// saveStudentsAs writes the edited student list to a new file, which becomes
+// the loaded student file.
+func (g *GUI) saveStudentsAs(path string) error {
+	if g.loadedTable == nil {
+		return errors.New("no students to save")
+	}
+	if err := g.loadedTable.ToCSV(path); err != nil {
+		return err
+	}
+	g.loadedCSVPath = path
+	g.project.SetStudentFile(path)
+	if err := g.saveTaskPoints(); err != nil {
+		return err
+	}
+	g.setDirty(false)
+	g.removeRecovery()
+	g.addRecentFile(path)
+	return nil
+}
@@ -0,0 +1,324 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/andreaswillibaldweber/gogrades/internal/project"
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+// Preference keys of the session state. The recovery keys get the id of the
+// exam tab as suffix.
+const (
+	prefRecentFiles    = "recentFiles"
+	prefRecoverySource = "recoverySource"
+	prefRecoveryPMax   = "recoveryPMax"
+	prefRecoveryPPass  = "recoveryPPass"
+)
+
+const (
+	recoveryFilePrefix = "recovery-"
+	recoveryFileExt    = ".csv"
+)
+
+func (g *GUI) preferences() fyne.Preferences {
+	return fyne.CurrentApp().Preferences()
+}
+
+// addRecentFile moves path to the top of the recent files list.
+func (g *GUI) addRecentFile(path string) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	recent := slices.DeleteFunc(g.preferences().StringList(prefRecentFiles), func(p string) bool { return p == path })
+	recent = append([]string{path}, recent...)
+	if len(recent) > recentFilesMax {
+		recent = recent[:recentFilesMax]
+	}
+	g.preferences().SetStringList(prefRecentFiles, recent)
+	g.rememberDirectory(path)
+	g.ws.updateRecentMenu()
+}
+
+func (ws *workspace) clearRecentFiles() {
+	ws.preferences().SetStringList(prefRecentFiles, []string{})
+	ws.updateRecentMenu()
+}
+
+func (ws *workspace) buildRecentMenu() *fyne.MenuItem {
+	ws.recentItem = fyne.NewMenuItem("Open Recent", nil)
+	ws.updateRecentMenu()
+	return ws.recentItem
+}
+
+func (ws *workspace) updateRecentMenu() {
+	if ws.recentItem == nil {
+		return
+	}
+	items := make([]*fyne.MenuItem, 0)
+	for _, path := range ws.preferences().StringList(prefRecentFiles) {
+		items = append(items, fyne.NewMenuItem(path, ws.on(func(g *GUI) { g.openRecentFile(path) })))
+	}
+	if len(items) == 0 {
+		empty := fyne.NewMenuItem("No recent files", nil)
+		empty.Disabled = true
+		items = append(items, empty)
+	}
+	items = append(items, fyne.NewMenuItemSeparator(), fyne.NewMenuItem("Clear Recent Files", ws.clearRecentFiles))
+	ws.recentItem.ChildMenu = fyne.NewMenu("", items...)
+	if menu := ws.window.MainMenu(); menu != nil {
+		menu.Refresh()
+	}
+}
+
+func (g *GUI) openRecentFile(path string) {
+	g.confirmDiscard(func() {
+		var err error
+		if project.IsProjectFile(path) {
+			err = g.loadProjectPath(path)
+		} else {
+			err = g.loadCSVPath(path)
+		}
+		if err != nil {
+			dialog.ShowError(err, g.window)
+		}
+	})
+}
+
+// recoveryPath is the autosave file of one exam tab in the app storage.
+func recoveryPath(id int) string {
+	name := recoveryFilePrefix + strconv.Itoa(id) + recoveryFileExt
+	return filepath.Join(fyne.CurrentApp().Storage().RootURI().Path(), name)
+}
+
+// recoveryTaskPointsPath does not end in "-<id>.csv", so recoveryIDs skips it.
+func recoveryTaskPointsPath(id int) string {
+	return strings.TrimSuffix(recoveryPath(id), recoveryFileExt) + "-" + project.OutputTaskPoints + recoveryFileExt
+}
+
+func recoveryKey(key string, id int) string {
+	return key + "." + strconv.Itoa(id)
+}
+
+// recoveryIDs lists the tabs of earlier sessions that left a recovery file.
+func recoveryIDs() []int {
+	entries, err := os.ReadDir(fyne.CurrentApp().Storage().RootURI().Path())
+	if err != nil {
+		return nil
+	}
+	ids := make([]int, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, recoveryFilePrefix) || !strings.HasSuffix(name, recoveryFileExt) {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, recoveryFilePrefix), recoveryFileExt))
+		if err == nil {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// lastRecoveryID keeps the ids of new tabs clear of recovery files that are
+// still to be offered.
+func lastRecoveryID() int {
+	ids := recoveryIDs()
+	if len(ids) == 0 {
+		return 0
+	}
+	return ids[len(ids)-1]
+}
+
+// startAutosave writes the student list of every tab with unsaved changes to
+// its recovery file.
+func (ws *workspace) startAutosave() {
+	ticker := time.NewTicker(autosaveInterval)
+	done := make(chan struct{})
+	ws.autosave, ws.autosaveDone = ticker, done
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				fyne.Do(func() {
+					for _, g := range ws.exams {
+						g.autosaveNow()
+					}
+				})
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// stopAutosave stops the ticker and ends the autosave goroutine; a stopped
+// ticker never closes its channel.
+func (ws *workspace) stopAutosave() {
+	if ws.autosave == nil {
+		return
+	}
+	ws.autosave.Stop()
+	close(ws.autosaveDone)
+	ws.autosave, ws.autosaveDone = nil, nil
+}
+
+func (g *GUI) autosaveNow() {
+	if !g.dirty || g.loadedTable == nil {
+		return
+	}
+	path := recoveryPath(g.id)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		g.statusLabel.SetText(fmt.Sprintf("Autosave failed: %v", err))
+		return
+	}
+	if err := g.loadedTable.ToCSV(path); err != nil {
+		g.statusLabel.SetText(fmt.Sprintf("Autosave failed: %v", err))
+		return
+	}
+	if len(g.taskPoints) > 0 && len(g.project.Tasks()) > 0 {
+		if err := writeTaskPoints(recoveryTaskPointsPath(g.id), g.project.Tasks(), g.taskPoints); err != nil {
+			g.statusLabel.SetText(fmt.Sprintf("Autosave failed: %v", err))
+			return
+		}
+	}
+	g.preferences().SetString(recoveryKey(prefRecoverySource, g.id), g.loadedCSVPath)
+	g.preferences().SetFloat(recoveryKey(prefRecoveryPMax, g.id), g.pMax)
+	g.preferences().SetFloat(recoveryKey(prefRecoveryPPass, g.id), g.pPass)
+	g.statusLabel.SetText(fmt.Sprintf("Autosaved at %s", time.Now().Format("15:04")))
+}
+
+func (g *GUI) removeRecovery() {
+	if err := removeRecovery(g.id); err != nil {
+		g.statusLabel.SetText(fmt.Sprintf("Remove recovery file: %v", err))
+	}
+}
+
+func removeRecovery(id int) error {
+	prefs := fyne.CurrentApp().Preferences()
+	prefs.RemoveValue(recoveryKey(prefRecoverySource, id))
+	prefs.RemoveValue(recoveryKey(prefRecoveryPMax, id))
+	prefs.RemoveValue(recoveryKey(prefRecoveryPPass, id))
+	for _, path := range []string{recoveryPath(id), recoveryTaskPointsPath(id)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// offerRecovery asks on start, one recovery file after the other, whether the
+// autosaved students of tabs that were closed without saving should be
+// restored. Restored lists go into the empty first tab or into new tabs.
+func (ws *workspace) offerRecovery() {
+	ws.offerRecoveryOf(recoveryIDs())
+}
+
+func (ws *workspace) offerRecoveryOf(ids []int) {
+	if len(ids) == 0 {
+		return
+	}
+	id, rest := ids[0], ids[1:]
+	info, err := os.Stat(recoveryPath(id))
+	if err != nil {
+		ws.offerRecoveryOf(rest)
+		return
+	}
+	source := ws.preferences().String(recoveryKey(prefRecoverySource, id))
+	name := "an unsaved student list"
+	if strings.TrimSpace(source) != "" {
+		name = source
+	}
+	message := fmt.Sprintf("GoGrades was closed with unsaved changes to\n%s\n(autosaved %s). Restore them?", name, info.ModTime().Format("2006-01-02 15:04"))
+	dialog.ShowConfirm("Recover unsaved changes", message, func(ok bool) {
+		if ok {
+			g := ws.current
+			if g == nil || g.dirty || g.loadedTable != nil {
+				ws.newExamTab()
+				g = ws.current
+			}
+			if err := g.restoreRecovery(id, source); err != nil {
+				dialog.ShowError(fmt.Errorf("recover: %w", err), ws.window)
+				ws.offerRecoveryOf(rest)
+				return
+			}
+		}
+		if err := removeRecovery(id); err != nil {
+			dialog.ShowError(fmt.Errorf("remove recovery file: %w", err), ws.window)
+		}
+		ws.offerRecoveryOf(rest)
+	}, ws.window)
+}
+
+// restoreRecovery loads the recovery file of the tab id of an earlier
+// session; the next autosave writes it under the id of g.
+func (g *GUI) restoreRecovery(id int, source string) error {
+	table, err := utilities.NewTableFromCSV(recoveryPath(id))
+	if err != nil {
+		return fmt.Errorf("read recovery file: %w", err)
+	}
+	points, err := readTaskPoints(recoveryTaskPointsPath(id), g.project.Tasks())
+	if err != nil {
+		return fmt.Errorf("read recovery file: %w", err)
+	}
+	previous := g.loadedTable
+	g.loadedTable = table
+	g.loadedCSVPath = source
+	g.project.SetStudentFile(source)
+	if pMax := g.preferences().Float(recoveryKey(prefRecoveryPMax, id)); pMax > 0 {
+		g.pMax = pMax
+		g.pPass = g.preferences().Float(recoveryKey(prefRecoveryPPass, id))
+		g.setSettingsText()
+	}
+	if err := g.rebuildTables(); err != nil {
+		g.loadedTable = previous
+		return fmt.Errorf("parse students: %w", err)
+	}
+	g.taskPoints = points
+	g.selectedMatNr = ""
+	g.renderTables()
+	g.syncSliders()
+	g.clearHistory()
+	g.setDirty(true)
+	g.statusLabel.SetText("Recovered unsaved changes, save the students to keep them")
+	return nil
+}
+
+// confirmDiscard runs next at once when there are no unsaved changes and
+// otherwise asks whether to save, discard or cancel.
+func (g *GUI) confirmDiscard(next func()) {
+	if !g.dirty {
+		next()
+		return
+	}
+	var d *dialog.CustomDialog
+	save := widget.NewButton("Save", func() {
+		d.Hide()
+		g.saveStudents()
+		if !g.dirty {
+			next()
+		}
+	})
+	save.Importance = widget.HighImportance
+	discard := widget.NewButton("Discard", func() {
+		d.Hide()
+		g.removeRecovery()
+		g.setDirty(false)
+		next()
+	})
+	cancel := widget.NewButton("Cancel", func() { d.Hide() })
+	d = dialog.NewCustomWithoutButtons("Unsaved changes", widget.NewLabel("The student list has unsaved changes."), g.window)
+	d.SetButtons([]fyne.CanvasObject{cancel, discard, save})
+	d.Show()
+}
@@ -1,8 +1,13 @@
 package gui
 
 import (
+	"cmp"
 	"fmt"
+	"image/color"
+	"math"
+	"sort"
 	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/widget"
@@ -11,15 +16,41 @@ import (
 
 type cellFormatter func(colIdx int, value any) string
 
+// rowFilter decides on the typed values of a source row whether it is shown.
+type rowFilter func(row utilities.TableRow) bool
+
+// tableAdapter shows a utilities.Table in a Fyne table. The formatted rows
+// are kept in source order; view lists the visible source rows after
+// filtering and sorting, which both work on the typed values.
 type tableAdapter struct {
 	table      *widget.Table
 	headers    []string
+	values     []utilities.TableRow
 	rows       [][]string
+	view       []int
+	sortable   bool
+	sortCol    int
+	sortAsc    bool
+	filter     rowFilter
 	rightAlign func(colIdx int) bool
+	onSelected func(rowIdx, colIdx int)
+	rowStyle   func(rowIdx int) (color.Color, string)
+	onHover    func(reason string)
+	// widths are the column widths set by resizeToFit, userWidths those the
+	// user dragged or that were restored from the preferences.
+	widths     map[int]float32
+	userWidths map[int]float32
 }
 
 func newTableAdapter(rightAlign func(colIdx int) bool) *tableAdapter {
-	t := &tableAdapter{headers: []string{}, rows: [][]string{}, rightAlign: rightAlign}
+	t := &tableAdapter{
+		headers:    []string{},
+		rows:       [][]string{},
+		sortCol:    -1,
+		rightAlign: rightAlign,
+		widths:     make(map[int]float32),
+		userWidths: make(map[int]float32),
+	}
 	t.table = t.newWidget()
 	return t
 }
@@ -30,44 +61,90 @@ func (t *tableAdapter) Widget() *widget.Table {
 
 func (t *tableAdapter) newWidget() *widget.Table {
 	table := widget.NewTableWithHeaders(
-		func() (int, int) { return len(t.rows), len(t.headers) },
-		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func() (int, int) { return len(t.view), len(t.headers) },
+		func() fyne.CanvasObject {
+			return newTableCell(func(reason string) {
+				if t.onHover != nil {
+					t.onHover(reason)
+				}
+			})
+		},
 		func(id widget.TableCellID, obj fyne.CanvasObject) {
-			label := obj.(*widget.Label)
-			if id.Row < 0 || id.Col < 0 || id.Row >= len(t.rows) || id.Col >= len(t.rows[id.Row]) {
-				label.SetText("")
+			cell := obj.(*tableCell)
+			row := t.sourceRow(id.Row)
+			if row < 0 || id.Col < 0 || id.Col >= len(t.rows[row]) {
+				cell.label.SetText("")
+				cell.setStyle(nil, "")
 				return
 			}
-			label.SetText(t.rows[id.Row][id.Col])
+			if t.rowStyle != nil {
+				cell.setStyle(t.rowStyle(row))
+			}
+			cell.label.Alignment = fyne.TextAlignLeading
 			if t.rightAlign(id.Col) {
-				label.Alignment = fyne.TextAlignTrailing
-				return
+				cell.label.Alignment = fyne.TextAlignTrailing
 			}
-			label.Alignment = fyne.TextAlignLeading
+			cell.label.SetText(t.rows[row][id.Col])
 		},
 	)
+	table.OnSelected = func(id widget.TableCellID) {
+		if row := t.sourceRow(id.Row); t.onSelected != nil && row >= 0 && id.Col >= 0 {
+			t.onSelected(row, id.Col)
+		}
+	}
 	table.CreateHeader = t.createHeader
 	table.UpdateHeader = t.updateHeader
 	table.SetColumnWidth(-1, defaultRowHeader)
 	return table
 }
 
+// sourceRow maps a visible row to its index in the source table.
+func (t *tableAdapter) sourceRow(visible int) int {
+	if visible < 0 || visible >= len(t.view) {
+		return -1
+	}
+	return t.view[visible]
+}
+
+// headerLabel is a bold label that reacts to clicks, used to sort by column.
+type headerLabel struct {
+	widget.Label
+	onTapped func()
+}
+
+func newHeaderLabel() *headerLabel {
+	h := &headerLabel{}
+	h.TextStyle = fyne.TextStyle{Bold: true}
+	h.ExtendBaseWidget(h)
+	return h
+}
+
+func (h *headerLabel) Tapped(*fyne.PointEvent) {
+	if h.onTapped != nil {
+		h.onTapped()
+	}
+}
+
 func (t *tableAdapter) createHeader() fyne.CanvasObject {
-	label := widget.NewLabel("")
-	label.TextStyle = fyne.TextStyle{Bold: true}
-	return label
+	return newHeaderLabel()
 }
 
 func (t *tableAdapter) updateHeader(id widget.TableCellID, obj fyne.CanvasObject) {
-	label := obj.(*widget.Label)
+	label := obj.(*headerLabel)
+	label.onTapped = nil
 	switch {
 	case id.Row == -1 && id.Col >= 0:
 		label.Alignment = fyne.TextAlignCenter
-		if id.Col < len(t.headers) {
-			label.SetText(t.headers[id.Col])
+		if id.Col >= len(t.headers) {
+			label.SetText("")
 			return
 		}
-		label.SetText("")
+		label.SetText(t.headers[id.Col] + t.sortMarker(id.Col))
+		t.headerWidth(id.Col, obj.Size().Width)
+		if t.sortable {
+			col := id.Col
+			label.onTapped = func() { t.sortBy(col) }
+		}
 	case id.Col == -1 && id.Row >= 0:
 		label.Alignment = fyne.TextAlignTrailing
 		label.SetText(strconv.Itoa(id.Row + 1))
@@ -77,12 +154,23 @@ func (t *tableAdapter) updateHeader(id widget.TableCellID, obj fyne.CanvasObject
 	}
 }
 
+func (t *tableAdapter) sortMarker(colIdx int) string {
+	if colIdx != t.sortCol {
+		return ""
+	}
+	if t.sortAsc {
+		return " ▲"
+	}
+	return " ▼"
+}
+
 func (t *tableAdapter) setData(src *utilities.Table, formatter cellFormatter) {
 	headers := src.Headers()
 	rows := src.Rows()
 
 	t.headers = make([]string, len(headers))
 	copy(t.headers, headers)
+	t.values = make([]utilities.TableRow, len(rows))
 	t.rows = make([][]string, len(rows))
 
 	for rowIdx, row := range rows {
@@ -92,17 +180,110 @@ func (t *tableAdapter) setData(src *utilities.Table, formatter cellFormatter) {
 				formattedRow[colIdx] = formatter(colIdx, row[colIdx])
 			}
 		}
+		t.values[rowIdx] = row
 		t.rows[rowIdx] = formattedRow
 	}
+	if t.sortCol >= len(t.headers) {
+		t.sortCol = -1
+	}
+	t.applyView()
+}
+
+// sortBy sorts by a column; a second click on the same column reverses the
+// order.
+func (t *tableAdapter) sortBy(colIdx int) {
+	if t.sortCol == colIdx {
+		t.sortAsc = !t.sortAsc
+	} else {
+		t.sortCol = colIdx
+		t.sortAsc = true
+	}
+	t.applyView()
+	t.table.Refresh()
+}
+
+func (t *tableAdapter) setFilter(filter rowFilter) {
+	t.filter = filter
+	t.applyView()
+	t.table.Refresh()
+}
+
+func (t *tableAdapter) applyView() {
+	t.view = make([]int, 0, len(t.values))
+	for rowIdx, row := range t.values {
+		if t.filter == nil || t.filter(row) {
+			t.view = append(t.view, rowIdx)
+		}
+	}
+	if t.sortCol < 0 {
+		return
+	}
+	sort.SliceStable(t.view, func(i, j int) bool {
+		c := compareValues(cellAt(t.values[t.view[i]], t.sortCol), cellAt(t.values[t.view[j]], t.sortCol))
+		if t.sortAsc {
+			return c < 0
+		}
+		return c > 0
+	})
+}
+
+// visibleRows is the number of rows passing the filter.
+func (t *tableAdapter) visibleRows() int {
+	return len(t.view)
 }
 
 func (t *tableAdapter) clear() {
 	t.headers = []string{}
+	t.values = []utilities.TableRow{}
 	t.rows = [][]string{}
+	t.view = []int{}
 	t.table.SetColumnWidth(-1, defaultRowHeader)
 	t.table.Refresh()
 }
 
+func cellAt(row utilities.TableRow, colIdx int) any {
+	if colIdx < 0 || colIdx >= len(row) {
+		return nil
+	}
+	return row[colIdx]
+}
+
+// compareValues orders numbers numerically and everything else as
+// case-insensitive text. Text sorts before numbers, so empty cells of a number
+// column come first.
+func compareValues(a, b any) int {
+	fa, okA := numericValue(a)
+	fb, okB := numericValue(b)
+	switch {
+	case okA && okB:
+		return cmp.Compare(fa, fb)
+	case okA:
+		return 1
+	case okB:
+		return -1
+	}
+	return strings.Compare(strings.ToLower(textValue(a)), strings.ToLower(textValue(b)))
+}
+
+func numericValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func textValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
 func (t *tableAdapter) resizeToFit(availableWidth float32) {
 	if len(t.headers) == 0 {
 		t.table.SetColumnWidth(-1, defaultRowHeader)
@@ -119,11 +300,45 @@ func (t *tableAdapter) resizeToFit(availableWidth float32) {
 		if width < 56 {
 			width = 56
 		}
+		if userWidth, ok := t.userWidths[colIdx]; ok {
+			width = userWidth
+		}
+		t.widths[colIdx] = width
 		t.table.SetColumnWidth(colIdx, width)
 	}
 	t.table.SetColumnWidth(-1, rowHeaderWidth)
 }
 
+// headerWidth is called with the laid out width of a column header. A width
+// that differs from the one set by resizeToFit was dragged by the user and is
+// kept on the next resize.
+func (t *tableAdapter) headerWidth(colIdx int, width float32) {
+	set, ok := t.widths[colIdx]
+	if !ok || width <= 0 || math.Abs(float64(set-width)) < 1 {
+		return
+	}
+	t.widths[colIdx] = width
+	t.userWidths[colIdx] = width
+}
+
+func (t *tableAdapter) setUserWidths(widths []float64) {
+	t.userWidths = make(map[int]float32)
+	for colIdx, width := range widths {
+		if width > 0 {
+			t.userWidths[colIdx] = float32(width)
+		}
+	}
+}
+
+// userWidthList lists the user widths by column, 0 for fitted columns.
+func (t *tableAdapter) userWidthList() []float64 {
+	widths := make([]float64, len(t.headers))
+	for colIdx := range widths {
+		widths[colIdx] = float64(t.userWidths[colIdx])
+	}
+	return widths
+}
+
 func (t *tableAdapter) baseColumnWidths() ([]float32, float32) {
 	base := make([]float32, len(t.headers))
 	total := float32(0)
@@ -187,16 +402,17 @@ func formatGradedCell(colIdx int, value any) string {
 	return fmt.Sprintf("%v", value)
 }
 
-func formatGradingKeyCell(colIdx int, value any) string {
+func formatPlainCell(colIdx int, value any) string {
+	return fmt.Sprintf("%v", value)
+}
+
+func formatDistributionCell(colIdx int, value any) string {
 	v, ok := value.(float64)
 	if !ok {
 		return fmt.Sprintf("%v", value)
 	}
-	if colIdx == 1 || colIdx == 3 {
+	if colIdx == 0 {
 		return fmt.Sprintf("%.1f", v)
 	}
-	if colIdx == 2 {
-		return fmt.Sprintf("%.1f%%", v)
-	}
 	return fmt.Sprintf("%v", value)
 }
@@ -0,0 +1,113 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/project"
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+)
+
+// The student file only stores the total points, so the points per task of
+// the point entry are kept next to it in a file with one column per task,
+// e.g. students-task-points.csv.
+
+func (g *GUI) taskPointsPath() string {
+	if strings.TrimSpace(g.loadedCSVPath) == "" {
+		return ""
+	}
+	return g.project.OutputPathExt(project.OutputTaskPoints, ".csv")
+}
+
+// saveTaskPoints writes the task points next to the saved student file.
+// Without tasks in the project the point entry only asks for totals and
+// there is nothing to keep.
+func (g *GUI) saveTaskPoints() error {
+	path := g.taskPointsPath()
+	if path == "" || len(g.taskPoints) == 0 || len(g.project.Tasks()) == 0 {
+		return nil
+	}
+	if err := writeTaskPoints(path, g.project.Tasks(), g.taskPoints); err != nil {
+		return fmt.Errorf("save task points: %w", err)
+	}
+	return nil
+}
+
+// loadTaskPoints reads the task points of the loaded student file, if any.
+func (g *GUI) loadTaskPoints() error {
+	g.taskPoints = nil
+	path := g.taskPointsPath()
+	if path == "" || len(g.project.Tasks()) == 0 {
+		return nil
+	}
+	points, err := readTaskPoints(path, g.project.Tasks())
+	if err != nil {
+		return fmt.Errorf("load task points: %w", err)
+	}
+	g.taskPoints = points
+	return nil
+}
+
+func writeTaskPoints(path string, tasks []project.Task, points map[string][]float64) error {
+	header := []string{studentHeaders[studentColMatNr]}
+	for _, task := range tasks {
+		header = append(header, task.Name())
+	}
+	matNrs := make([]string, 0, len(points))
+	for matNr, values := range points {
+		if len(values) == len(tasks) {
+			matNrs = append(matNrs, matNr)
+		}
+	}
+	sort.Strings(matNrs)
+	rows := make([]utilities.TableRow, 0, len(matNrs))
+	for _, matNr := range matNrs {
+		row := utilities.TableRow{matNr}
+		for _, v := range points[matNr] {
+			row = append(row, v)
+		}
+		rows = append(rows, row)
+	}
+	return utilities.NewTable(header, rows).ToCSV(path)
+}
+
+// readTaskPoints returns no points for a missing file or one written for
+// other tasks; the point entry then starts from the totals again.
+func readTaskPoints(path string, tasks []project.Task) (map[string][]float64, error) {
+	table, err := utilities.ReadPlainCSV(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	header := table.Headers()
+	if len(header) != len(tasks)+1 {
+		return nil, nil
+	}
+	for i, task := range tasks {
+		if strings.TrimSpace(header[i+1]) != task.Name() {
+			return nil, nil
+		}
+	}
+
+	points := make(map[string][]float64)
+	for row := range table.Rows() {
+		matNr := strings.TrimSpace(cellText(table, row, 0))
+		values := make([]float64, len(tasks))
+		for i := range tasks {
+			text := strings.TrimSpace(cellText(table, row, i+1))
+			v, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s, line %d: invalid points %q", path, row+2, text)
+			}
+			values[i] = v
+		}
+		points[matNr] = values
+	}
+	return points, nil
+}
@@ -0,0 +1,201 @@
+package gui
+
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/andreaswillibaldweber/gogrades/internal/project"
+)
+
+// workspace is the main window with one tab per exam and a comparison tab.
+// It owns the menus, which always act on the active exam.
+type workspace struct {
+	window  fyne.Window
+	tabs    *container.DocTabs
+	exams   []*GUI
+	current *GUI
+	nextID  int
+
+	compareTab   *container.TabItem
+	compareTable *tableAdapter
+	compareLabel *widget.Label
+
+	undoItem       *fyne.MenuItem
+	redoItem       *fyne.MenuItem
+	highlightItems map[string]*fyne.MenuItem
+	themeItems     map[string]*fyne.MenuItem
+	recentItem     *fyne.MenuItem
+	autosave       *time.Ticker
+	autosaveDone   chan struct{}
+}
+
+func newWorkspace() *workspace {
+	a := app.NewWithID("gogrades")
+	ws := &workspace{
+		window: a.NewWindow("GoGrades"),
+		nextID: lastRecoveryID() + 1,
+	}
+	ws.window.Resize(fyne.NewSize(windowWidth, windowHeight))
+
+	ws.compareTab = ws.buildCompareTab()
+	ws.tabs = container.NewDocTabs(ws.compareTab)
+	ws.tabs.CloseIntercept = ws.closeTab
+	ws.tabs.OnSelected = ws.tabSelected
+
+	ws.window.SetMainMenu(ws.buildMainMenu())
+	ws.window.SetContent(ws.tabs)
+	ws.window.SetCloseIntercept(ws.closeWindow)
+	ws.window.SetOnDropped(func(pos fyne.Position, uris []fyne.URI) {
+		if ws.current != nil {
+			ws.current.filesDropped(pos, uris)
+		}
+	})
+	ws.restoreWindowState()
+	return ws
+}
+
+func (ws *workspace) preferences() fyne.Preferences {
+	return fyne.CurrentApp().Preferences()
+}
+
+// addExam opens proj in a new tab in front of the comparison tab and selects
+// it.
+func (ws *workspace) addExam(proj project.Project) *GUI {
+	g := newGUI(ws, proj, ws.nextID)
+	ws.nextID++
+	g.tab = container.NewTabItem(g.tabName(), g.content)
+	ws.exams = append(ws.exams, g)
+
+	items := slices.DeleteFunc(slices.Clone(ws.tabs.Items), func(item *container.TabItem) bool {
+		return item == ws.compareTab
+	})
+	items = append(items, g.tab)
+	if ws.comparisonShown() {
+		items = append(items, ws.compareTab)
+	}
+	ws.tabs.SetItems(items)
+	ws.tabs.Select(g.tab)
+	ws.tabSelected(g.tab)
+	return g
+}
+
+// newExamTab opens an empty exam with the points and grading settings of the
+// active one, e.g. for the retake of the same course.
+func (ws *workspace) newExamTab() {
+	current := ws.current
+	if current == nil {
+		return
+	}
+	proj := project.New(current.pMax, current.pPass, "")
+	proj.SetRounding(current.project.Step(), current.project.Rounding(), current.project.Granularity())
+	proj.SetGradeSet(current.project.AllowedGrades(), current.project.GradeMapping())
+	proj.SetHighlights(current.project.Highlights(), current.project.TopGrade())
+	g := ws.addExam(proj)
+	if err := g.rebuildTables(); err == nil {
+		g.renderTables()
+	}
+}
+
+func (ws *workspace) examOf(item *container.TabItem) *GUI {
+	for _, g := range ws.exams {
+		if g.tab == item {
+			return g
+		}
+	}
+	return nil
+}
+
+func (ws *workspace) tabSelected(item *container.TabItem) {
+	if item == ws.compareTab {
+		ws.renderComparison()
+		return
+	}
+	if g := ws.examOf(item); g != nil {
+		ws.current = g
+		g.activate()
+	}
+}
+
+// closeTab asks about unsaved changes before an exam tab is closed; the last
+// exam tab is replaced by an empty one. The comparison tab is only hidden,
+// View -> Comparison shows it again.
+func (ws *workspace) closeTab(item *container.TabItem) {
+	if item == ws.compareTab {
+		ws.tabs.Remove(item)
+		if ws.current != nil {
+			ws.tabs.Select(ws.current.tab)
+			ws.tabSelected(ws.current.tab)
+		}
+		return
+	}
+	g := ws.examOf(item)
+	if g == nil {
+		return
+	}
+	g.confirmDiscard(func() { ws.removeExam(g) })
+}
+
+func (ws *workspace) closeCurrentTab() {
+	if ws.current != nil {
+		ws.closeTab(ws.current.tab)
+	}
+}
+
+func (ws *workspace) removeExam(g *GUI) {
+	g.saveState()
+	if len(ws.exams) == 1 {
+		ws.newExamTab()
+	}
+	ws.exams = slices.DeleteFunc(ws.exams, func(e *GUI) bool { return e == g })
+	ws.tabs.Remove(g.tab)
+	if ws.current == g {
+		last := ws.exams[len(ws.exams)-1]
+		ws.tabs.Select(last.tab)
+		ws.tabSelected(last.tab)
+	}
+}
+
+// closeWindow walks through the tabs with unsaved changes before the window
+// is closed; cancelling any of them keeps the window open.
+func (ws *workspace) closeWindow() {
+	for _, g := range ws.exams {
+		if g.dirty {
+			ws.tabs.Select(g.tab)
+			ws.tabSelected(g.tab)
+			g.confirmDiscard(ws.closeWindow)
+			return
+		}
+	}
+	ws.stopAutosave()
+	ws.saveState()
+	ws.window.Close()
+}
+
+// tabName is the course of the project or else the name of the student file.
+func (g *GUI) tabName() string {
+	if course := strings.TrimSpace(g.project.Course()); course != "" {
+		return course
+	}
+	if path := strings.TrimSpace(g.loadedCSVPath); path != "" {
+		return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return "Exam " + strconv.Itoa(g.id)
+}
+
+func (g *GUI) isActive() bool {
+	return g.ws != nil && g.ws.current == g
+}
+
+// activate updates the window title and the menus for the selected tab.
+func (g *GUI) activate() {
+	g.setDirty(g.dirty)
+	g.updateEditMenu()
+	g.ws.updateViewMenu()
+}
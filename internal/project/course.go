@@ -0,0 +1,152 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andreaswillibaldweber/gogrades/internal/grades"
+)
+
+type Component struct {
+	name     string
+	weight   float64
+	mustPass bool
+	project  Project
+}
+
+func (c Component) Name() string {
+	return c.name
+}
+
+func (c Component) Weight() float64 {
+	return c.weight
+}
+
+func (c Component) MustPass() bool {
+	return c.mustPass
+}
+
+func (c Component) Project() Project {
+	return c.project
+}
+
+type Course struct {
+	path       string
+	name       string
+	components []Component
+	allowed    []float64
+	mapping    string
+}
+
+// courseFile is the on-disk layout of a course. A component either points to
+// an exam project file or describes its exam inline.
+type courseFile struct {
+	Course     string          `toml:"course" yaml:"course"`
+	PassEach   bool            `toml:"passeach" yaml:"passeach"`
+	Allowed    []float64       `toml:"allowed,omitempty" yaml:"allowed,omitempty"`
+	Mapping    string          `toml:"mapping,omitempty" yaml:"mapping,omitempty"`
+	Components []componentFile `toml:"components" yaml:"components"`
+}
+
+type componentFile struct {
+	Name     string  `toml:"name" yaml:"name"`
+	Weight   float64 `toml:"weight" yaml:"weight"`
+	MustPass bool    `toml:"mustpass" yaml:"mustpass"`
+	Project  string  `toml:"project,omitempty" yaml:"project,omitempty"`
+	Input    string  `toml:"input,omitempty" yaml:"input,omitempty"`
+	PMax     float64 `toml:"pmax,omitempty" yaml:"pmax,omitempty"`
+	PPass    float64 `toml:"ppass,omitempty" yaml:"ppass,omitempty"`
+}
+
+func LoadCourse(path string) (Course, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Course{}, fmt.Errorf("read course: %w", err)
+	}
+
+	var file courseFile
+	if err := decode(path, data, &file); err != nil {
+		return Course{}, fmt.Errorf("parse course: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	c := Course{path: path, name: file.Course, allowed: file.Allowed, mapping: file.Mapping}
+	if c.mapping == "" {
+		c.mapping = grades.DefaultGradeMapping
+	}
+	for _, cf := range file.Components {
+		proj, err := componentProject(cf, dir)
+		if err != nil {
+			return Course{}, fmt.Errorf("component %q: %w", cf.Name, err)
+		}
+		c.components = append(c.components, Component{
+			name:     cf.Name,
+			weight:   cf.Weight,
+			mustPass: cf.MustPass || file.PassEach,
+			project:  proj,
+		})
+	}
+	if err := c.Validate(); err != nil {
+		return Course{}, err
+	}
+	return c, nil
+}
+
+func componentProject(cf componentFile, dir string) (Project, error) {
+	if cf.Project != "" {
+		if cf.Input != "" || cf.PMax != 0 || cf.PPass != 0 {
+			return Project{}, fmt.Errorf("%w: use either project or input/pmax/ppass", ErrInvalid)
+		}
+		return Load(resolvePath(dir, cf.Project))
+	}
+	proj := New(cf.PMax, cf.PPass, resolvePath(dir, cf.Input))
+	return proj, proj.Validate()
+}
+
+func (c Course) Validate() error {
+	if len(c.components) == 0 {
+		return fmt.Errorf("%w: course needs at least one component", ErrInvalid)
+	}
+	names := make(map[string]bool)
+	for _, comp := range c.components {
+		if strings.TrimSpace(comp.name) == "" {
+			return fmt.Errorf("%w: component without name", ErrInvalid)
+		}
+		if names[comp.name] {
+			return fmt.Errorf("%w: duplicate component %q", ErrInvalid, comp.name)
+		}
+		names[comp.name] = true
+		if comp.weight <= 0 {
+			return fmt.Errorf("%w: weight of component %q must be > 0", ErrInvalid, comp.name)
+		}
+		if strings.TrimSpace(comp.project.StudentFile()) == "" {
+			return fmt.Errorf("%w: component %q has no student file", ErrInvalid, comp.name)
+		}
+	}
+	if _, err := grades.NewGradeSet(c.allowed, c.mapping); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return nil
+}
+
+func (c Course) Path() string {
+	return c.path
+}
+
+func (c Course) Name() string {
+	return c.name
+}
+
+func (c Course) Components() []Component {
+	return c.components
+}
+
+func (c Course) AllowedGrades() []float64 {
+	return c.allowed
+}
+
+func (c Course) GradeMapping() string {
+	return c.mapping
+}
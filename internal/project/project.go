@@ -0,0 +1,543 @@
+package project
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/andreaswillibaldweber/gogrades/internal/grades"
+	"github.com/andreaswillibaldweber/gogrades/internal/utilities"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SchemeLinear = "linear"
+)
+
+var ErrInvalid = errors.New("invalid project")
+
+const (
+	DefaultTemplate  = "{base}-{kind}.csv"
+	OutputGraded     = "graded"
+	OutputGradingKey = "grading-key"
+	OutputHISinOne   = "hisinone"
+	OutputReconcile  = "reconciliation"
+	OutputRetake     = "retake"
+	OutputReport     = "report"
+	OutputPrint      = "print"
+	OutputTaskPoints = "task-points"
+)
+
+type Task struct {
+	name string
+	max  float64
+}
+
+func NewTask(name string, max float64) Task {
+	return Task{name: name, max: max}
+}
+
+func (t Task) Name() string {
+	return t.name
+}
+
+func (t Task) Max() float64 {
+	return t.max
+}
+
+type Project struct {
+	path        string
+	course      string
+	date        string
+	examiners   []string
+	pMax        float64
+	pPass       float64
+	scheme      string
+	step        string
+	rounding    string
+	granularity float64
+	allowed     []float64
+	mapping     string
+	highlight   []string
+	topGrade    float64
+	tasks       []Task
+	bonus       string
+	corrections string
+	input       string
+	outDir      string
+	template    string
+}
+
+// projectFile is the on-disk layout of a project. Paths are stored relative
+// to the project file so a project folder can be moved as a whole.
+type projectFile struct {
+	Course      string     `toml:"course" yaml:"course"`
+	Date        string     `toml:"date" yaml:"date"`
+	Examiners   []string   `toml:"examiners" yaml:"examiners"`
+	PMax        float64    `toml:"pmax" yaml:"pmax"`
+	PPass       float64    `toml:"ppass" yaml:"ppass"`
+	Scheme      string     `toml:"scheme" yaml:"scheme"`
+	Step        string     `toml:"step,omitempty" yaml:"step,omitempty"`
+	Rounding    string     `toml:"rounding,omitempty" yaml:"rounding,omitempty"`
+	Granularity float64    `toml:"granularity,omitempty" yaml:"granularity,omitempty"`
+	Allowed     []float64  `toml:"allowed,omitempty" yaml:"allowed,omitempty"`
+	Mapping     string     `toml:"mapping,omitempty" yaml:"mapping,omitempty"`
+	Highlight   []string   `toml:"highlight,omitempty" yaml:"highlight,omitempty"`
+	TopGrade    float64    `toml:"topgrade,omitempty" yaml:"topgrade,omitempty"`
+	Tasks       []taskFile `toml:"tasks,omitempty" yaml:"tasks,omitempty"`
+	Bonus       string     `toml:"bonus,omitempty" yaml:"bonus,omitempty"`
+	Corrections string     `toml:"corrections,omitempty" yaml:"corrections,omitempty"`
+	Input       string     `toml:"input" yaml:"input"`
+	OutDir      string     `toml:"outdir,omitempty" yaml:"outdir,omitempty"`
+	Template    string     `toml:"template,omitempty" yaml:"template,omitempty"`
+}
+
+type taskFile struct {
+	Name string  `toml:"name" yaml:"name"`
+	Max  float64 `toml:"max" yaml:"max"`
+}
+
+func New(pMax, pPass float64, input string) Project {
+	return Project{
+		pMax:        pMax,
+		pPass:       pPass,
+		scheme:      SchemeLinear,
+		step:        grades.DefaultStep,
+		rounding:    grades.DefaultRounding,
+		granularity: grades.DefaultGranularity,
+		mapping:     grades.DefaultGradeMapping,
+		highlight:   grades.DefaultHighlights,
+		topGrade:    grades.DefaultTopGrade,
+		input:       input,
+		template:    DefaultTemplate,
+	}
+}
+
+func IsProjectFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+func Load(path string) (Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Project{}, fmt.Errorf("read project: %w", err)
+	}
+
+	var file projectFile
+	if err := decode(path, data, &file); err != nil {
+		return Project{}, fmt.Errorf("parse project: %w", err)
+	}
+
+	p := fromFile(file, filepath.Dir(path))
+	p.path = path
+	if err := p.Validate(); err != nil {
+		return Project{}, err
+	}
+	return p, nil
+}
+
+func decode(path string, data []byte, v any) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		md, err := toml.Decode(string(data), v)
+		if err != nil {
+			return err
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("unknown key %q", undecoded[0].String())
+		}
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(v); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported file %q (use .toml, .yaml or .yml)", path)
+	}
+	return nil
+}
+
+func (p Project) Save(path string) error {
+	file := p.toFile(filepath.Dir(path))
+
+	var buf bytes.Buffer
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		if err := toml.NewEncoder(&buf).Encode(file); err != nil {
+			return fmt.Errorf("encode project: %w", err)
+		}
+	case ".yaml", ".yml":
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(file); err != nil {
+			return fmt.Errorf("encode project: %w", err)
+		}
+		encoder.Close()
+	default:
+		return fmt.Errorf("unsupported project file %q (use .toml, .yaml or .yml)", path)
+	}
+
+	err := utilities.WriteFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(buf.Bytes())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("write project: %w", err)
+	}
+	return nil
+}
+
+func (p Project) Validate() error {
+	if p.pMax <= 0 {
+		return fmt.Errorf("%w: max points must be > 0", ErrInvalid)
+	}
+	if p.pPass < 0 {
+		return fmt.Errorf("%w: pass points must be >= 0", ErrInvalid)
+	}
+	if p.pPass >= p.pMax {
+		return fmt.Errorf("%w: pass points must be smaller than max points", ErrInvalid)
+	}
+	if p.scheme != SchemeLinear {
+		return fmt.Errorf("%w: unknown grading scheme %q", ErrInvalid, p.scheme)
+	}
+	if _, err := grades.NewRounding(p.step, p.rounding, p.granularity); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if _, err := grades.NewGradeSet(p.allowed, p.mapping); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if _, err := grades.NewHighlighter(p.highlight, p.topGrade); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if !strings.Contains(p.template, "{kind}") {
+		return fmt.Errorf("%w: output template %q must contain {kind}", ErrInvalid, p.template)
+	}
+	if len(p.tasks) > 0 {
+		total := 0.0
+		for _, t := range p.tasks {
+			total += t.max
+		}
+		if total < p.pMax {
+			return fmt.Errorf("%w: task maxima add up to %.1f, less than max points %.1f", ErrInvalid, total, p.pMax)
+		}
+	}
+	return nil
+}
+
+func fromFile(file projectFile, dir string) Project {
+	p := Project{
+		course:      file.Course,
+		date:        file.Date,
+		examiners:   file.Examiners,
+		pMax:        file.PMax,
+		pPass:       file.PPass,
+		scheme:      file.Scheme,
+		step:        file.Step,
+		rounding:    file.Rounding,
+		granularity: file.Granularity,
+		allowed:     file.Allowed,
+		mapping:     file.Mapping,
+		highlight:   file.Highlight,
+		topGrade:    file.TopGrade,
+		bonus:       resolvePath(dir, file.Bonus),
+		corrections: resolvePath(dir, file.Corrections),
+		input:       resolvePath(dir, file.Input),
+		outDir:      resolvePath(dir, file.OutDir),
+		template:    file.Template,
+	}
+	if p.scheme == "" {
+		p.scheme = SchemeLinear
+	}
+	if p.step == "" {
+		p.step = grades.DefaultStep
+	}
+	if p.rounding == "" {
+		p.rounding = grades.DefaultRounding
+	}
+	if p.granularity == 0 {
+		p.granularity = grades.DefaultGranularity
+	}
+	if p.mapping == "" {
+		p.mapping = grades.DefaultGradeMapping
+	}
+	if p.highlight == nil {
+		p.highlight = grades.DefaultHighlights
+	}
+	if p.topGrade == 0 {
+		p.topGrade = grades.DefaultTopGrade
+	}
+	if p.template == "" {
+		p.template = DefaultTemplate
+	}
+	for _, t := range file.Tasks {
+		p.tasks = append(p.tasks, NewTask(t.Name, t.Max))
+	}
+	return p
+}
+
+func (p Project) toFile(dir string) projectFile {
+	file := projectFile{
+		Course:      p.course,
+		Date:        p.date,
+		Examiners:   p.examiners,
+		PMax:        p.pMax,
+		PPass:       p.pPass,
+		Scheme:      p.scheme,
+		Bonus:       relativePath(dir, p.bonus),
+		Corrections: relativePath(dir, p.corrections),
+		Input:       relativePath(dir, p.input),
+		OutDir:      relativePath(dir, p.outDir),
+	}
+	if p.step != grades.DefaultStep {
+		file.Step = p.step
+	}
+	if p.rounding != grades.DefaultRounding {
+		file.Rounding = p.rounding
+	}
+	if p.granularity != grades.DefaultGranularity {
+		file.Granularity = p.granularity
+	}
+	file.Allowed = p.allowed
+	if p.mapping != grades.DefaultGradeMapping {
+		file.Mapping = p.mapping
+	}
+	if !slices.Equal(p.highlight, grades.DefaultHighlights) {
+		file.Highlight = p.highlight
+		if len(p.highlight) == 0 {
+			file.Highlight = []string{grades.HighlightNone}
+		}
+	}
+	if p.topGrade != grades.DefaultTopGrade {
+		file.TopGrade = p.topGrade
+	}
+	if p.template != DefaultTemplate {
+		file.Template = p.template
+	}
+	for _, t := range p.tasks {
+		file.Tasks = append(file.Tasks, taskFile{Name: t.name, Max: t.max})
+	}
+	return file
+}
+
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+func relativePath(dir, path string) string {
+	if path == "" {
+		return path
+	}
+	absDir, errDir := filepath.Abs(dir)
+	absPath, errPath := filepath.Abs(path)
+	if errDir != nil || errPath != nil {
+		return path
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (p Project) Path() string {
+	return p.path
+}
+
+func (p Project) Course() string {
+	return p.course
+}
+
+func (p Project) Date() string {
+	return p.date
+}
+
+func (p Project) Examiners() []string {
+	return p.examiners
+}
+
+func (p Project) PMax() float64 {
+	return p.pMax
+}
+
+func (p Project) PPass() float64 {
+	return p.pPass
+}
+
+func (p Project) Scheme() string {
+	return p.scheme
+}
+
+func (p Project) Step() string {
+	return p.step
+}
+
+func (p Project) Rounding() string {
+	return p.rounding
+}
+
+func (p Project) Granularity() float64 {
+	return p.granularity
+}
+
+func (p Project) AllowedGrades() []float64 {
+	return p.allowed
+}
+
+func (p Project) GradeMapping() string {
+	return p.mapping
+}
+
+func (p Project) Highlights() []string {
+	return p.highlight
+}
+
+func (p Project) TopGrade() float64 {
+	return p.topGrade
+}
+
+func (p Project) Tasks() []Task {
+	return p.tasks
+}
+
+func (p Project) BonusFile() string {
+	return p.bonus
+}
+
+func (p Project) CorrectionsFile() string {
+	return p.corrections
+}
+
+func (p Project) StudentFile() string {
+	return p.input
+}
+
+func (p Project) OutDir() string {
+	return p.outDir
+}
+
+func (p Project) Template() string {
+	return p.template
+}
+
+// OutputPath expands the output template for one kind of output file. The
+// template knows {base} (student file name without extension), {kind},
+// {course} and {date}.
+func (p Project) OutputPath(kind string) string {
+	base := strings.TrimSuffix(filepath.Base(p.input), filepath.Ext(p.input))
+	name := strings.NewReplacer(
+		"{base}", base,
+		"{kind}", kind,
+		"{course}", p.course,
+		"{date}", p.date,
+	).Replace(p.template)
+
+	dir := p.outDir
+	if strings.TrimSpace(dir) == "" {
+		dir = filepath.Dir(p.input)
+	}
+	return filepath.Join(dir, name)
+}
+
+// OutputPathExt is OutputPath with the file extension replaced, e.g. for an
+// HTML report next to the CSV outputs.
+func (p Project) OutputPathExt(kind, ext string) string {
+	path := p.OutputPath(kind)
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ext
+}
+
+// ReportTitle heads the HTML report and the printed grade list: the course,
+// or the name of the student file without a course.
+func (p Project) ReportTitle() string {
+	if course := strings.TrimSpace(p.course); course != "" {
+		return course
+	}
+	if strings.TrimSpace(p.input) == "" {
+		return "Students"
+	}
+	return filepath.Base(p.input)
+}
+
+// ReportDate is the exam date, or today without one.
+func (p Project) ReportDate() string {
+	if date := strings.TrimSpace(p.date); date != "" {
+		return date
+	}
+	return time.Now().Format("2006-01-02")
+}
+
+// ReportHeading is the title and date in one line, e.g. for the HTML report.
+func (p Project) ReportHeading() string {
+	return p.ReportTitle() + " " + p.ReportDate()
+}
+
+// PrintPage is the page header and footer of the printed grade list.
+func (p Project) PrintPage(signature bool) utilities.PrintPage {
+	return utilities.PrintPage{Title: p.ReportTitle(), Date: p.ReportDate(), Signature: signature, Signers: p.examiners}
+}
+
+func (p *Project) SetTemplate(template string) {
+	p.template = template
+}
+
+func (p *Project) SetPath(path string) {
+	p.path = path
+}
+
+func (p *Project) SetPoints(pMax, pPass float64) {
+	p.pMax = pMax
+	p.pPass = pPass
+}
+
+func (p *Project) SetRounding(step, rounding string, granularity float64) {
+	p.step = step
+	p.rounding = rounding
+	p.granularity = granularity
+}
+
+func (p *Project) SetGradeSet(allowed []float64, mapping string) {
+	p.allowed = allowed
+	p.mapping = mapping
+}
+
+func (p *Project) SetHighlights(rules []string, topGrade float64) {
+	p.highlight = rules
+	p.topGrade = topGrade
+}
+
+func (p *Project) SetStudentFile(path string) {
+	p.input = path
+}
+
+func (p *Project) SetBonusFile(path string) {
+	p.bonus = path
+}
+
+func (p *Project) SetCorrectionsFile(path string) {
+	p.corrections = path
+}
+
+func (p *Project) SetOutDir(path string) {
+	p.outDir = path
+}
+
+func (p Project) String() string {
+	return fmt.Sprintf(
+		"Project{course: %q, date: %q, examiners: %q, pmax: %v, ppass: %v, scheme: %q, step: %q, rounding: %q, granularity: %v, allowed: %v, mapping: %q, highlight: %q, topgrade: %v, tasks: %d, bonus: %q, corrections: %q, input: %q, outdir: %q, template: %q}",
+		p.course, p.date, p.examiners, p.pMax, p.pPass, p.scheme, p.step, p.rounding, p.granularity, p.allowed, p.mapping, p.highlight, p.topGrade, len(p.tasks), p.bonus, p.corrections, p.input, p.outDir, p.template,
+	)
+}
@@ -37,40 +37,149 @@ func readCSVFromReader(r io.Reader) (*Table, error) {
 			return NewEmptyTable([]string{}), fmt.Errorf("read row: %w", err)
 		}
 
-		if len(row) < 4 {
-			return NewEmptyTable([]string{}), fmt.Errorf("invalid row: expected at least 4 columns (name, matNr, seatNr, points), got %d", len(row))
+		tableRow, err := studentRow(row)
+		if err != nil {
+			return NewEmptyTable([]string{}), err
 		}
+		table.AddRow(tableRow)
+	}
+
+	return table, nil
+}
+
+// studentRow converts the fields name, matNr, seatNr, points and an optional
+// comment of a student file.
+func studentRow(row []string) (TableRow, error) {
+	if len(row) < 4 {
+		return nil, fmt.Errorf("invalid row: expected at least 4 columns (name, matNr, seatNr, points), got %d", len(row))
+	}
+
+	points, err := strconv.ParseFloat(strings.TrimSpace(row[3]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse points for %q: %w", row[0], err)
+	}
+
+	comment := ""
+	if len(row) > 4 {
+		comment = strings.TrimSpace(row[4])
+	}
+
+	return TableRow{strings.TrimSpace(row[0]), strings.TrimSpace(row[1]), strings.TrimSpace(row[2]), points, comment}, nil
+}
+
+func ReadPlainCSV(filepath string) (*Table, error) {
+	f, err := os.Open(filepath)
+	if err != nil {
+		return NewEmptyTable([]string{}), fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	return readPlainCSVFromReader(f)
+}
 
-		points, err := strconv.ParseFloat(strings.TrimSpace(row[3]), 64)
+func readPlainCSVFromReader(r io.Reader) (*Table, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return NewEmptyTable([]string{}), fmt.Errorf("read header: %w", err)
+	}
+
+	table := NewEmptyTable(header)
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			return NewEmptyTable([]string{}), fmt.Errorf("parse points for %q: %w", row[0], err)
+			return NewEmptyTable([]string{}), fmt.Errorf("read row: %w", err)
 		}
 
-		comment := ""
-		if len(row) > 4 {
-			comment = strings.TrimSpace(row[4])
+		tableRow := make(TableRow, len(row))
+		for i, cell := range row {
+			tableRow[i] = strings.TrimSpace(cell)
 		}
-
-		table.AddRow(TableRow{strings.TrimSpace(row[0]), strings.TrimSpace(row[1]), strings.TrimSpace(row[2]), points, comment})
+		table.AddRow(tableRow)
 	}
 
 	return table, nil
 }
 
-func WriteCSV(filepath string, table Table) error {
-	f, err := os.Create(filepath)
+type CSVLine struct {
+	number int
+	fields []string
+}
+
+func (l CSVLine) Number() int {
+	return l.number
+}
+
+func (l CSVLine) Fields() []string {
+	return l.fields
+}
+
+func ReadCSVLines(filepath string) ([]CSVLine, error) {
+	f, err := os.Open(filepath)
 	if err != nil {
-		return fmt.Errorf("create file: %w", err)
+		return []CSVLine{}, fmt.Errorf("open file: %w", err)
 	}
 	defer f.Close()
 
-	return writeCSVToWriter(f, table)
+	return readCSVLinesFromReader(f)
+}
+
+func readCSVLinesFromReader(r io.Reader) ([]CSVLine, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	lines := make([]CSVLine, 0)
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return lines, fmt.Errorf("read row: %w", err)
+		}
+		number, _ := reader.FieldPos(0)
+		lines = append(lines, CSVLine{number: number, fields: row})
+	}
+
+	return lines, nil
+}
+
+func WriteCSV(filepath string, table Table) error {
+	return WriteFileAtomic(filepath, func(w io.Writer) error {
+		return writeCSVToWriter(w, table)
+	})
+}
+
+func WriteCSVWithPreamble(filepath string, preamble [][]string, delimiter rune, table Table) error {
+	return WriteFileAtomic(filepath, func(w io.Writer) error {
+		return writeCSVToWriterWithPreamble(w, preamble, delimiter, table)
+	})
+}
+
+func WriteCSVTo(w io.Writer, table Table) error {
+	return writeCSVToWriter(w, table)
 }
 
 func writeCSVToWriter(w io.Writer, table Table) error {
+	return writeCSVToWriterWithPreamble(w, nil, ',', table)
+}
+
+func writeCSVToWriterWithPreamble(w io.Writer, preamble [][]string, delimiter rune, table Table) error {
 	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
 	defer writer.Flush()
 
+	for _, line := range preamble {
+		if err := writer.Write(line); err != nil {
+			return fmt.Errorf("write preamble: %w", err)
+		}
+	}
+
 	if err := writer.Write(table.header); err != nil {
 		return fmt.Errorf("write header: %w", err)
 	}
@@ -89,3 +198,36 @@ func writeCSVToWriter(w io.Writer, table Table) error {
 
 	return nil
 }
+
+// AppendCSVRow appends one record to a CSV file and writes the header first
+// if the file is new or empty. Existing records are never rewritten.
+func AppendCSVRow(filepath string, header []string, row []string) error {
+	f, err := os.OpenFile(filepath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat file: %w", err)
+	}
+
+	writer := csv.NewWriter(f)
+	if info.Size() == 0 {
+		if err := writer.Write(header); err != nil {
+			return fmt.Errorf("write header: %w", err)
+		}
+	}
+	if err := writer.Write(row); err != nil {
+		return fmt.Errorf("write row: %w", err)
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+	return nil
+}
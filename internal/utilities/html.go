@@ -0,0 +1,84 @@
+package utilities
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// HTMLSection is one table of an HTML report. RowClasses and RowTitles are
+// optional and give each data row a CSS class and a tooltip.
+type HTMLSection struct {
+	Title      string
+	Table      Table
+	RowClasses []string
+	RowTitles  []string
+}
+
+type htmlRow struct {
+	Class string
+	Title string
+	Cells []string
+}
+
+type htmlSection struct {
+	Title   string
+	Headers []string
+	Rows    []htmlRow
+}
+
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+{{.Style}}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- range .Sections}}
+<h2>{{.Title}}</h2>
+<table>
+<thead><tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr{{if .Class}} class="{{.Class}}"{{end}}{{if .Title}} title="{{.Title}}"{{end}}>{{range .Cells}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTMLTo writes a standalone HTML page with one table per section. The
+// cells are formatted like the text tables; style is embedded as CSS.
+func WriteHTMLTo(w io.Writer, title, style string, sections []HTMLSection) error {
+	data := struct {
+		Title    string
+		Style    template.CSS
+		Sections []htmlSection
+	}{Title: title, Style: template.CSS(style)}
+
+	for _, section := range sections {
+		out := htmlSection{Title: section.Title, Headers: section.Table.header}
+		for i, row := range section.Table.rows {
+			r := htmlRow{Cells: section.Table.formatRowStrings(row)}
+			if i < len(section.RowClasses) {
+				r.Class = section.RowClasses[i]
+			}
+			if i < len(section.RowTitles) {
+				r.Title = section.RowTitles[i]
+			}
+			out.Rows = append(out.Rows, r)
+		}
+		data.Sections = append(data.Sections, out)
+	}
+
+	if err := htmlReport.Execute(w, data); err != nil {
+		return fmt.Errorf("write HTML: %w", err)
+	}
+	return nil
+}
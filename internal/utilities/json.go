@@ -0,0 +1,27 @@
+package utilities
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+func WriteJSONTo(w io.Writer, table Table) error {
+	records := make([]map[string]any, 0, len(table.rows))
+	for _, row := range table.rows {
+		record := make(map[string]any, len(table.header))
+		for i, h := range table.header {
+			if i < len(row) {
+				record[h] = row[i]
+			}
+		}
+		records = append(records, record)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(records); err != nil {
+		return fmt.Errorf("encode JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+package utilities
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PrintSection is one table of a printed report.
+type PrintSection struct {
+	Title string
+	Table Table
+}
+
+// PrintPage is the page header and footer of a printed report. With
+// Signature set every page ends with one signature line per signer, or with
+// a single unnamed line when there are no signers.
+type PrintPage struct {
+	Title     string
+	Date      string
+	Signature bool
+	Signers   []string
+}
+
+// A4 in PDF points (1/72 inch).
+const (
+	pdfPageWidth      = 595.28
+	pdfPageHeight     = 841.89
+	pdfMargin         = 42.0
+	pdfHeaderHeight   = 30.0
+	pdfFooterHeight   = 56.0
+	pdfTitleSize      = 11.0
+	pdfSmallSize      = 8.0
+	pdfTableSize      = 9.0
+	pdfMinTableSize   = 6.0
+	pdfCellPadding    = 3.0
+	pdfCourierAdvance = 0.6
+	pdfLineSpacing    = 1.5
+	pdfSignatureGap   = 24.0
+)
+
+// The standard fonts need not be embedded. Tables use Courier, so a column is
+// as wide as its longest cell in characters.
+const (
+	fontSans     = "/F1"
+	fontSansBold = "/F2"
+	fontMono     = "/F3"
+	fontMonoBold = "/F4"
+)
+
+var pdfFonts = []string{"Helvetica", "Helvetica-Bold", "Courier", "Courier-Bold"}
+
+// winAnsiExtra are the characters of Windows code page 1252 outside Latin-1.
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// WritePDFTo lays out the sections as tables on A4 pages and writes them as a
+// PDF document. A table that continues on the next page repeats its header
+// row; tables too wide for the page get a smaller font and cut cells.
+func WritePDFTo(w io.Writer, page PrintPage, sections []PrintSection) error {
+	l := &pdfLayout{page: page, bottom: pdfMargin}
+	if page.Signature {
+		l.bottom += pdfFooterHeight
+	}
+	l.newPage()
+	for _, section := range sections {
+		l.table(section)
+	}
+	if _, err := w.Write(l.document()); err != nil {
+		return fmt.Errorf("write PDF: %w", err)
+	}
+	return nil
+}
+
+type pdfLayout struct {
+	page   PrintPage
+	pages  []*strings.Builder
+	y      float64
+	bottom float64
+}
+
+func (l *pdfLayout) newPage() {
+	l.pages = append(l.pages, &strings.Builder{})
+	l.y = pdfPageHeight - pdfMargin - pdfHeaderHeight
+}
+
+func (l *pdfLayout) out() *strings.Builder {
+	return l.pages[len(l.pages)-1]
+}
+
+// text draws s, which must already be encoded by winAnsi.
+func (l *pdfLayout) text(font string, size, x, y float64, s string) {
+	fmt.Fprintf(l.out(), "BT %s %s Tf %s %s Td (%s) Tj ET\n", font, pdfNum(size), pdfNum(x), pdfNum(y), pdfEscape(s))
+}
+
+func (l *pdfLayout) rect(x, y, width, height, gray float64) {
+	fmt.Fprintf(l.out(), "%s g %s %s %s %s re f 0 g\n", pdfNum(gray), pdfNum(x), pdfNum(y), pdfNum(width), pdfNum(height))
+}
+
+func (l *pdfLayout) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(l.out(), "%s w %s %s m %s %s l S\n", pdfNum(width), pdfNum(x1), pdfNum(y1), pdfNum(x2), pdfNum(y2))
+}
+
+func (l *pdfLayout) title(text string) {
+	l.text(fontSansBold, pdfTitleSize, pdfMargin, l.y-pdfTitleSize, winAnsi(text))
+	l.y -= 2 * pdfTitleSize
+}
+
+func (l *pdfLayout) table(section PrintSection) {
+	t := newPDFTable(section.Table)
+	if l.y-2*pdfTitleSize-2*t.rowHeight() < l.bottom {
+		l.newPage()
+	}
+	l.title(section.Title)
+	l.tableRow(t, t.headers, true, false)
+	for i, row := range t.rows {
+		if l.y-t.rowHeight() < l.bottom {
+			l.newPage()
+			l.title(section.Title + " (continued)")
+			l.tableRow(t, t.headers, true, false)
+		}
+		l.tableRow(t, row, false, i%2 == 1)
+	}
+	l.y -= pdfTitleSize
+}
+
+func (l *pdfLayout) tableRow(t pdfTable, cells []string, header, shaded bool) {
+	height := t.rowHeight()
+	l.y -= height
+	switch {
+	case header:
+		l.rect(pdfMargin, l.y, t.width(), height, 0.85)
+	case shaded:
+		l.rect(pdfMargin, l.y, t.width(), height, 0.95)
+	}
+	font := fontMono
+	if header {
+		font = fontMonoBold
+	}
+	baseline := l.y + (height-t.size)/2 + 0.2*t.size
+	x := pdfMargin
+	for i, cell := range cells {
+		if len(cell) > t.chars[i] {
+			cell = cell[:t.chars[i]]
+		}
+		cellX := x + pdfCellPadding
+		if t.right[i] {
+			cellX = x + t.columnWidth(i) - pdfCellPadding - pdfCourierAdvance*t.size*float64(len(cell))
+		}
+		l.text(font, t.size, cellX, baseline, cell)
+		x += t.columnWidth(i)
+	}
+	if header {
+		l.line(pdfMargin, l.y, pdfMargin+t.width(), l.y, 0.8)
+	}
+}
+
+// decoration is the page header and the signature footer of page n of total.
+func (l *pdfLayout) decoration(n, total int) string {
+	d := &pdfLayout{pages: []*strings.Builder{{}}}
+	top := pdfPageHeight - pdfMargin
+	d.text(fontSansBold, pdfTitleSize, pdfMargin, top-pdfTitleSize, winAnsi(l.page.Title))
+	right := fmt.Sprintf("Page %d of %d", n, total)
+	if date := strings.TrimSpace(l.page.Date); date != "" {
+		right = date + "   " + right
+	}
+	right = winAnsi(right)
+	d.text(fontMono, pdfTableSize, pdfPageWidth-pdfMargin-pdfCourierAdvance*pdfTableSize*float64(len(right)), top-pdfTitleSize, right)
+	d.line(pdfMargin, top-pdfTitleSize-6, pdfPageWidth-pdfMargin, top-pdfTitleSize-6, 0.5)
+
+	if l.page.Signature {
+		signers := l.page.Signers
+		if len(signers) == 0 {
+			signers = []string{""}
+		}
+		width := (pdfPageWidth - 2*pdfMargin - pdfSignatureGap*float64(len(signers)-1)) / float64(len(signers))
+		for i, signer := range signers {
+			x := pdfMargin + float64(i)*(width+pdfSignatureGap)
+			d.line(x, pdfMargin+2*pdfSmallSize+4, x+width, pdfMargin+2*pdfSmallSize+4, 0.5)
+			label := "Date, signature"
+			if strings.TrimSpace(signer) != "" {
+				label += " " + strings.TrimSpace(signer)
+			}
+			d.text(fontSans, pdfSmallSize, x, pdfMargin+pdfSmallSize, winAnsi(label))
+		}
+	}
+	return d.out().String()
+}
+
+// document writes the pages with the catalog, page tree, fonts and cross
+// reference table of a PDF 1.4 file.
+func (l *pdfLayout) document() []byte {
+	var buf bytes.Buffer
+	offsets := make([]int, 0)
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	firstFont := 3
+	firstPage := firstFont + len(pdfFonts)
+	kids := make([]string, len(l.pages))
+	for i := range l.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(l.pages)))
+	fonts := make([]string, len(pdfFonts))
+	for i, name := range pdfFonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fonts[i] = fmt.Sprintf("/F%d %d 0 R", i+1, firstFont+i)
+	}
+	resources := "<< /Font << " + strings.Join(fonts, " ") + " >> >>"
+	for i, page := range l.pages {
+		content := l.decoration(i+1, len(l.pages)) + page.String()
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>",
+			pdfNum(pdfPageWidth), pdfNum(pdfPageHeight), resources, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// pdfTable is a table with its cells encoded for the standard fonts and the
+// column widths in characters.
+type pdfTable struct {
+	headers []string
+	rows    [][]string
+	right   []bool
+	chars   []int
+	size    float64
+}
+
+func newPDFTable(table Table) pdfTable {
+	t := pdfTable{
+		headers: make([]string, len(table.header)),
+		right:   make([]bool, len(table.header)),
+		chars:   make([]int, len(table.header)),
+	}
+	for i, header := range table.header {
+		t.headers[i] = winAnsi(header)
+		t.chars[i] = len(t.headers[i])
+	}
+	for _, row := range table.rows {
+		cells := table.formatRowStrings(row)
+		out := make([]string, len(t.headers))
+		for i := range out {
+			if i >= len(cells) {
+				continue
+			}
+			out[i] = winAnsi(cells[i])
+			t.chars[i] = max(t.chars[i], len(out[i]))
+			switch row[i].(type) {
+			case float64, int:
+				t.right[i] = true
+			}
+		}
+		t.rows = append(t.rows, out)
+	}
+	t.fit(pdfPageWidth - 2*pdfMargin)
+	return t
+}
+
+// fit picks the largest font size up to pdfTableSize at which the table fits
+// width. Below pdfMinTableSize the widest columns are cut instead.
+func (t *pdfTable) fit(width float64) {
+	padding := 2 * pdfCellPadding * float64(len(t.chars))
+	total := 0
+	for _, c := range t.chars {
+		total += c
+	}
+	t.size = pdfTableSize
+	if total == 0 {
+		return
+	}
+	t.size = min(pdfTableSize, (width-padding)/(pdfCourierAdvance*float64(total)))
+	if t.size >= pdfMinTableSize {
+		return
+	}
+	t.size = pdfMinTableSize
+	limit := int((width - padding) / (pdfCourierAdvance * pdfMinTableSize))
+	for total > limit && total > 0 {
+		widest := 0
+		for i, c := range t.chars {
+			if c > t.chars[widest] {
+				widest = i
+			}
+		}
+		t.chars[widest]--
+		total--
+	}
+}
+
+func (t pdfTable) rowHeight() float64 {
+	return t.size * pdfLineSpacing
+}
+
+func (t pdfTable) columnWidth(i int) float64 {
+	return pdfCourierAdvance*t.size*float64(t.chars[i]) + 2*pdfCellPadding
+}
+
+func (t pdfTable) width() float64 {
+	width := 0.0
+	for i := range t.chars {
+		width += t.columnWidth(i)
+	}
+	return width
+}
+
+// winAnsi encodes s for the standard fonts, one byte per character.
+// Characters outside Windows code page 1252 become '?'.
+func winAnsi(s string) string {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x20:
+			out = append(out, ' ')
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			out = append(out, byte(r))
+		default:
+			if b, ok := winAnsiExtra[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return string(out)
+}
+
+func pdfEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
+
+func pdfNum(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -1,6 +1,9 @@
 package utilities
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type FormatHook func(value float64) string
 
@@ -34,6 +37,15 @@ func (t Table) Headers() []string {
 	return t.header
 }
 
+func (t Table) ColumnIndex(header string) int {
+	for i, h := range t.header {
+		if strings.EqualFold(strings.TrimSpace(h), header) {
+			return i
+		}
+	}
+	return -1
+}
+
 func (t *Table) SetHeaders(header []string) Table {
 	t.header = header
 	return *t
@@ -71,6 +83,17 @@ func (t *Table) AddRow(row TableRow) Table {
 	return *t
 }
 
+// SetCell replaces one value; short rows are padded with empty strings.
+func (t *Table) SetCell(rowIdx, colIdx int, value any) {
+	if rowIdx < 0 || rowIdx >= len(t.rows) || colIdx < 0 {
+		return
+	}
+	for len(t.rows[rowIdx]) <= colIdx {
+		t.rows[rowIdx] = append(t.rows[rowIdx], "")
+	}
+	t.rows[rowIdx][colIdx] = value
+}
+
 func (t *Table) ClearRows() {
 	t.rows = []TableRow{}
 }
@@ -170,19 +193,22 @@ func (t Table) buildDataRows(widths []int, rightAlignCols []int) string {
 func (t Table) formatRowStrings(row TableRow) []string {
 	out := make([]string, len(row))
 	for i, cell := range row {
-		cellStr := fmt.Sprintf("%v", cell)
-		if t.formatHooks != nil {
-			if hook, ok := t.formatHooks[i]; ok && hook != nil {
-				if f, okf := cell.(float64); okf {
-					cellStr = hook(f)
-				}
-			}
-		}
-		out[i] = cellStr
+		out[i] = t.FormatCell(i, cell)
 	}
 	return out
 }
 
+// FormatCell formats one value of column colIdx with the format hook of the
+// column, as the table prints it.
+func (t Table) FormatCell(colIdx int, value any) string {
+	if hook, ok := t.formatHooks[colIdx]; ok && hook != nil {
+		if f, okf := value.(float64); okf {
+			return hook(f)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
 func buildTableRowFromStrings(formatted []string, widths []int, rightAlignCols []int) string {
 	line := "|"
 	for i, cellStr := range formatted {
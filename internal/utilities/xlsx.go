@@ -0,0 +1,309 @@
+package utilities
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ReadXLSX reads a student table from the first worksheet of an Excel
+// workbook. The sheet has the same columns as a student CSV file.
+func ReadXLSX(filepath string) (*Table, error) {
+	records, err := readXLSXRecords(filepath)
+	if err != nil {
+		return NewEmptyTable([]string{}), err
+	}
+	if len(records) == 0 {
+		return NewEmptyTable([]string{}), fmt.Errorf("read header: empty worksheet")
+	}
+
+	table := NewEmptyTable(records[0])
+	for _, row := range records[1:] {
+		tableRow, err := studentRow(row)
+		if err != nil {
+			return NewEmptyTable([]string{}), err
+		}
+		table.AddRow(tableRow)
+	}
+
+	return table, nil
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a shared or inline string, either plain or split into
+// formatted runs.
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	text := t.Text
+	for _, run := range t.Runs {
+		text += run.Text
+	}
+	return text
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxCell struct {
+	Ref    string   `xml:"r,attr"`
+	Type   string   `xml:"t,attr"`
+	Value  string   `xml:"v"`
+	Inline xlsxText `xml:"is"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []xlsxCell `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSXRecords returns the non-empty rows of the first worksheet as text,
+// like the records of a CSV file.
+func readXLSXRecords(filepath string) ([][]string, error) {
+	archive, err := zip.OpenReader(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer archive.Close()
+
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	var shared xlsxSharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXLSXPart(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+	var sheet xlsxWorksheet
+	if err := decodeXLSXPart(files, firstSheetPath(files), &sheet); err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		record := make([]string, 0, len(row.Cells))
+		for _, cell := range row.Cells {
+			col := len(record)
+			if ref, ok := xlsxColumn(cell.Ref); ok {
+				col = ref
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+			value, err := cell.text(shared)
+			if err != nil {
+				return nil, err
+			}
+			record[col] = value
+		}
+		if strings.TrimSpace(strings.Join(record, "")) != "" {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// firstSheetPath follows the workbook relationships to the first worksheet
+// and falls back to the name Excel gives it.
+func firstSheetPath(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	if decodeXLSXPart(files, "xl/workbook.xml", &workbook) != nil || len(workbook.Sheets) == 0 ||
+		decodeXLSXPart(files, "xl/_rels/workbook.xml.rels", &rels) != nil {
+		return fallback
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+func decodeXLSXPart(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("read workbook: missing %s", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("read workbook: %w", err)
+	}
+	defer r.Close()
+
+	if err := xml.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("read workbook %s: %w", name, err)
+	}
+	return nil
+}
+
+func (c xlsxCell) text(shared xlsxSharedStrings) (string, error) {
+	switch c.Type {
+	case "s":
+		index, err := strconv.Atoi(strings.TrimSpace(c.Value))
+		if err != nil || index < 0 || index >= len(shared.Items) {
+			return "", fmt.Errorf("cell %s: invalid shared string %q", c.Ref, c.Value)
+		}
+		return shared.Items[index].String(), nil
+	case "inlineStr":
+		return c.Inline.String(), nil
+	case "", "n":
+		number, err := strconv.ParseFloat(strings.TrimSpace(c.Value), 64)
+		if err != nil {
+			return c.Value, nil
+		}
+		// Excel shows 15 significant digits, e.g. 0.3 instead of the stored
+		// 0.30000000000000004.
+		number, _ = strconv.ParseFloat(strconv.FormatFloat(number, 'g', 15, 64), 64)
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	default:
+		return c.Value, nil
+	}
+}
+
+// xlsxColumn returns the zero-based column of a cell reference such as "C12".
+func xlsxColumn(ref string) (int, bool) {
+	col := 0
+	letters := 0
+	for _, r := range strings.ToUpper(ref) {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = 26*col + int(r-'A'+1)
+		letters++
+	}
+	return col - 1, letters > 0
+}
+
+// The parts of the smallest workbook Excel and LibreOffice open: one sheet
+// with inline strings, so no shared strings or styles are needed.
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxWorkbookStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`
+	xlsxWorkbookEnd = `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxSheetStart  = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// WriteXLSXTo writes the preamble lines, the header and the rows of table to
+// one worksheet. Numbers become number cells and everything else text cells,
+// so e.g. matriculation numbers keep leading zeros.
+func WriteXLSXTo(w io.Writer, sheet string, preamble [][]string, table Table) error {
+	var data bytes.Buffer
+	data.WriteString(xlsxSheetStart)
+	line := 0
+	writeRow := func(cells []any) {
+		line++
+		fmt.Fprintf(&data, `<row r="%d">`, line)
+		for i, cell := range cells {
+			ref := xlsxCellRef(i, line)
+			switch v := cell.(type) {
+			case int:
+				fmt.Fprintf(&data, `<c r="%s"><v>%d</v></c>`, ref, v)
+			case float64:
+				fmt.Fprintf(&data, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'f', -1, 64))
+			default:
+				text := fmt.Sprintf("%v", cell)
+				if text == "" {
+					continue
+				}
+				fmt.Fprintf(&data, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+				xml.EscapeText(&data, []byte(text))
+				data.WriteString(`</t></is></c>`)
+			}
+		}
+		data.WriteString(`</row>`)
+	}
+	for _, fields := range preamble {
+		writeRow(stringCells(fields))
+	}
+	writeRow(stringCells(table.header))
+	for _, row := range table.rows {
+		writeRow(row)
+	}
+	data.WriteString(xlsxSheetEnd)
+
+	var name bytes.Buffer
+	xml.EscapeText(&name, []byte(sheet))
+	parts := []struct {
+		name, content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbookStart + name.String() + xlsxWorkbookEnd},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/worksheets/sheet1.xml", data.String()},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, part := range parts {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("write workbook: %w", err)
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return fmt.Errorf("write workbook %s: %w", part.name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("write workbook: %w", err)
+	}
+	return nil
+}
+
+func stringCells(fields []string) []any {
+	cells := make([]any, len(fields))
+	for i, field := range fields {
+		cells[i] = field
+	}
+	return cells
+}
+
+// xlsxCellRef is the reference of the zero-based column col in the one-based
+// row, e.g. "AB3".
+func xlsxCellRef(col, row int) string {
+	letters := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		letters = string(rune('A'+(col-1)%26)) + letters
+	}
+	return letters + strconv.Itoa(row)
+}